@@ -20,3 +20,8 @@ type requestId string
 
 // SessionKey is the key used to store the session ID in the context.
 const RequestIdKey = requestId("jsonrpc-request-id")
+
+type requestInfoKey string
+
+// RequestInfoKey is the key used to store the RequestInfo in the context.
+const RequestInfoKey = requestInfoKey("jsonrpc-request-info")
@@ -0,0 +1,59 @@
+package jsonrpc
+
+import (
+	"context"
+	"net/http"
+)
+
+// Transport kinds reported by RequestInfo.
+const (
+	TransportStdio      = "stdio"
+	TransportSSE        = "sse"
+	TransportStreamable = "streamable"
+)
+
+// RequestInfo represents transport metadata of an inbound message exposed to handlers.
+type RequestInfo struct {
+	// Transport identifies the transport kind (stdio, sse, streamable).
+	Transport string
+	// SessionID is the transport session id (consistent across server and client).
+	SessionID string
+	// ProtocolVersion is the negotiated protocol version (MCP-Protocol-Version header), if known.
+	ProtocolVersion string
+	// RemoteAddr is the network address of the peer, if applicable.
+	RemoteAddr string
+	// Header contains only allow-listed request headers.
+	Header http.Header
+	// Grant holds the resolved auth grant (*auth.Grant) when BFF auth is configured.
+	Grant any
+	// Principal holds the authenticated bearer token principal (*auth.Principal) when bearer auth is configured.
+	Principal any
+}
+
+// WithRequestInfo returns a context carrying request info.
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, RequestInfoKey, info)
+}
+
+// RequestInfoFromContext returns request info from the context.
+func RequestInfoFromContext(ctx context.Context) (*RequestInfo, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	info, ok := ctx.Value(RequestInfoKey).(*RequestInfo)
+	return info, ok && info != nil
+}
+
+// SessionIDFromContext returns the transport session id from the context.
+func SessionIDFromContext(ctx context.Context) string {
+	if info, ok := RequestInfoFromContext(ctx); ok && info.SessionID != "" {
+		return info.SessionID
+	}
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(SessionKey).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -121,9 +121,10 @@ type Notification struct {
 // UnmarshalJSON is a custom JSON unmarshaler for the Notification type.
 func (m *Notification) UnmarshalJSON(data []byte) error {
 	required := struct {
-		Jsonrpc *string `json:"jsonrpc" yaml:"jsonrpc" mapstructure:"jsonrpc"`
-		Method  *string `json:"method" yaml:"method" mapstructure:"method"`
-		Id      *int64  `json:"id" yaml:"id" mapstructure:"id"`
+		Jsonrpc *string          `json:"jsonrpc" yaml:"jsonrpc" mapstructure:"jsonrpc"`
+		Method  *string          `json:"method" yaml:"method" mapstructure:"method"`
+		Id      *int64           `json:"id" yaml:"id" mapstructure:"id"`
+		Params  *json.RawMessage `json:"params" yaml:"params" mapstructure:"params"`
 	}{}
 	err := json.Unmarshal(data, &required)
 	if err != nil {
@@ -140,6 +141,9 @@ func (m *Notification) UnmarshalJSON(data []byte) error {
 	}
 	m.Jsonrpc = *required.Jsonrpc
 	m.Method = *required.Method
+	if required.Params != nil {
+		m.Params = *required.Params
+	}
 	return nil
 }
 
@@ -0,0 +1,210 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/viant/jsonrpc"
+)
+
+// ChunkNotification is the notification method carrying a partial result of a request
+const ChunkNotification = "notifications/chunk"
+
+// ErrChunksNotSupported is returned by WriteChunk outside of a request being served
+var ErrChunksNotSupported = errors.New("chunked result is not supported in this context")
+
+// ChunkParams represents chunk notification parameters; chunks of a request are numbered from 1
+type ChunkParams struct {
+	RequestId jsonrpc.RequestId `json:"requestId"`
+	Seq       int               `json:"seq"`
+	Data      json.RawMessage   `json:"data"`
+}
+
+// ChunkWriter emits a partial result of the current request
+type ChunkWriter func(ctx context.Context, data interface{}) error
+
+// ChunkHandler receives partial results of an outgoing request
+type ChunkHandler func(chunk *ChunkParams)
+
+type chunkKey string
+
+const (
+	chunkWriterKey  = chunkKey("jsonrpc-chunk-writer")
+	chunkHandlerKey = chunkKey("jsonrpc-chunk-handler")
+)
+
+// StreamRequested returns true if request params carry _meta.stream set by a streaming client
+func StreamRequested(request *jsonrpc.Request) bool {
+	if request == nil || len(request.Params) == 0 {
+		return false
+	}
+	params := struct {
+		Meta *struct {
+			Stream bool `json:"stream"`
+		} `json:"_meta"`
+	}{}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return false
+	}
+	return params.Meta != nil && params.Meta.Stream
+}
+
+// SetStreamRequested sets params._meta.stream on request with object (or empty) params
+func SetStreamRequested(request *jsonrpc.Request) error {
+	return setMeta(request, "stream", true)
+}
+
+// WithChunkWriter returns context with chunk writer of the current request
+func WithChunkWriter(ctx context.Context, writer ChunkWriter) context.Context {
+	return context.WithValue(ctx, chunkWriterKey, writer)
+}
+
+// WriteChunk emits a partial result of the request being served; chunks are delivered in order before the response
+func WriteChunk(ctx context.Context, data interface{}) error {
+	if ctx == nil {
+		return ErrChunksNotSupported
+	}
+	writer, ok := ctx.Value(chunkWriterKey).(ChunkWriter)
+	if !ok || writer == nil {
+		return ErrChunksNotSupported
+	}
+	return writer(ctx, data)
+}
+
+// WithChunkHandler returns context receiving partial results of a single client call
+func WithChunkHandler(ctx context.Context, handler ChunkHandler) context.Context {
+	return context.WithValue(ctx, chunkHandlerKey, handler)
+}
+
+// ChunkHandlerFromContext returns per-call chunk handler
+func ChunkHandlerFromContext(ctx context.Context) (ChunkHandler, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	handler, ok := ctx.Value(chunkHandlerKey).(ChunkHandler)
+	return handler, ok && handler != nil
+}
+
+// Stream represents a request with streamed partial results
+type Stream struct {
+	chunks   chan *ChunkParams
+	done     chan struct{}
+	notify   chan struct{}
+	mux      sync.Mutex
+	queue    []*ChunkParams
+	closed   bool
+	response *jsonrpc.Response
+	err      error
+}
+
+// Chunks returns channel of partial results; it is closed once the final response arrives and all chunks are delivered.
+// Chunks not yet drained are queued in memory, so a slow consumer never stalls the client read loop.
+func (s *Stream) Chunks() <-chan *ChunkParams {
+	return s.chunks
+}
+
+// Response waits for the final response
+func (s *Stream) Response() (*jsonrpc.Response, error) {
+	<-s.done
+	return s.response, s.err
+}
+
+// push queues chunk without blocking; it is called from the client read loop
+func (s *Stream) push(chunk *ChunkParams) {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		return
+	}
+	s.queue = append(s.queue, chunk)
+	s.mux.Unlock()
+	s.wake()
+}
+
+func (s *Stream) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// forward delivers queued chunks to the chunks channel and closes it once the stream is finished and drained,
+// or when ctx is done
+func (s *Stream) forward(ctx context.Context) {
+	defer close(s.chunks)
+	for {
+		s.mux.Lock()
+		if len(s.queue) == 0 {
+			closed := s.closed
+			s.mux.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-s.notify:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		chunk := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mux.Unlock()
+		select {
+		case s.chunks <- chunk:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Stream) finish(response *jsonrpc.Response, err error) {
+	s.mux.Lock()
+	s.closed = true
+	s.mux.Unlock()
+	s.wake()
+	s.response, s.err = response, err
+	close(s.done)
+}
+
+// SendStream sends request asking the server to stream its result; chunks are delivered on the stream
+// channel (buffered with the given size) followed by the final response.
+func SendStream(ctx context.Context, client Transport, request *jsonrpc.Request, buffer int) *Stream {
+	ret := &Stream{chunks: make(chan *ChunkParams, buffer), done: make(chan struct{}), notify: make(chan struct{}, 1)}
+	callCtx := WithChunkHandler(ctx, ret.push)
+	go ret.forward(ctx)
+	go func() {
+		response, err := client.Send(callCtx, request)
+		ret.finish(response, err)
+	}()
+	return ret
+}
+
+// setMeta sets params._meta[key] on request with object (or empty) params
+func setMeta(request *jsonrpc.Request, key string, value any) error {
+	params := map[string]json.RawMessage{}
+	if len(request.Params) > 0 && string(request.Params) != "null" {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return err
+		}
+	}
+	meta := map[string]any{}
+	if raw, ok := params["_meta"]; ok {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+	}
+	meta[key] = value
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	params["_meta"] = data
+	if request.Params, err = json.Marshal(params); err != nil {
+		return err
+	}
+	return nil
+}
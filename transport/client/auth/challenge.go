@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Challenge represents a parsed Bearer WWW-Authenticate challenge (RFC 6750, RFC 9728)
+type Challenge struct {
+	Scheme           string
+	Realm            string
+	Error            string
+	ErrorDescription string
+	Scope            string
+	// ResourceMetadata is the protected resource metadata URL advertised by the server.
+	ResourceMetadata string
+	// RequestURL is the URL of the rejected request; discovered resource metadata must match it.
+	RequestURL string
+	Params     map[string]string
+}
+
+// ParseChallenge parses WWW-Authenticate header value; it returns nil when no challenge is present
+func ParseChallenge(header string) *Challenge {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
+	}
+	scheme, rest, _ := strings.Cut(header, " ")
+	ret := &Challenge{Scheme: scheme, Params: map[string]string{}}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			value, rest = unquote(value[1:])
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			value = strings.TrimSpace(value)
+		}
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+		ret.Params[name] = value
+	}
+	ret.Realm = ret.Params["realm"]
+	ret.Error = ret.Params["error"]
+	ret.ErrorDescription = ret.Params["error_description"]
+	ret.Scope = ret.Params["scope"]
+	ret.ResourceMetadata = ret.Params["resource_metadata"]
+	return ret
+}
+
+// ResponseChallenge returns challenge of 401 response, or nil
+func ResponseChallenge(resp *http.Response) *Challenge {
+	if resp == nil {
+		return nil
+	}
+	ret := ParseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if ret != nil && resp.Request != nil && resp.Request.URL != nil {
+		ret.RequestURL = resp.Request.URL.String()
+	}
+	return ret
+}
+
+// unquote reads quoted-string content up to the closing quote and returns it with the remaining input
+func unquote(value string) (string, string) {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				builder.WriteByte(value[i])
+			}
+		case '"':
+			return builder.String(), value[i+1:]
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String(), ""
+}
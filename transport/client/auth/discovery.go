@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ResourceMetadata represents RFC 9728 protected resource metadata fetched by the client
+type ResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers,omitempty"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// ServerMetadata represents RFC 8414 authorization server metadata
+type ServerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint,omitempty"`
+	GrantTypesSupported   []string `json:"grant_types_supported,omitempty"`
+}
+
+// FetchResourceMetadata fetches protected resource metadata from metadataURL
+func FetchResourceMetadata(ctx context.Context, client *http.Client, metadataURL string) (*ResourceMetadata, error) {
+	ret := &ResourceMetadata{}
+	if err := getJSON(ctx, client, metadataURL, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// FetchServerMetadata fetches authorization server metadata for issuer, trying RFC 8414 then OpenID discovery;
+// metadata whose issuer differs from the requested one is rejected (RFC 8414 section 3.3)
+func FetchServerMetadata(ctx context.Context, client *http.Client, issuer string) (*ServerMetadata, error) {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid issuer %q", issuer)
+	}
+	issuerPath := strings.TrimSuffix(parsed.EscapedPath(), "/")
+	origin := parsed.Scheme + "://" + parsed.Host
+	candidates := []string{
+		origin + "/.well-known/oauth-authorization-server" + issuerPath,
+		origin + issuerPath + "/.well-known/openid-configuration",
+	}
+	var lastErr error
+	for _, candidate := range candidates {
+		ret := &ServerMetadata{}
+		if lastErr = getJSON(ctx, client, candidate, ret); lastErr != nil {
+			continue
+		}
+		if ret.Issuer != issuer {
+			return nil, fmt.Errorf("authorization server metadata issuer %q does not match %q", ret.Issuer, issuer)
+		}
+		if ret.TokenEndpoint != "" {
+			return ret, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("authorization server %v has no token endpoint", issuer)
+	}
+	return nil, lastErr
+}
+
+// DiscoverTokenEndpoint resolves token endpoint and resource from the challenge resource_metadata;
+// the advertised resource must match the challenged request URL (RFC 9728 section 3.3)
+func DiscoverTokenEndpoint(ctx context.Context, client *http.Client, challenge *Challenge) (tokenURL string, resource string, err error) {
+	if challenge == nil || challenge.ResourceMetadata == "" {
+		return "", "", fmt.Errorf("challenge does not advertise resource_metadata")
+	}
+	if challenge.RequestURL == "" {
+		return "", "", fmt.Errorf("challenge request URL is unknown")
+	}
+	metadata, err := FetchResourceMetadata(ctx, client, challenge.ResourceMetadata)
+	if err != nil {
+		return "", "", err
+	}
+	if !matchesResource(metadata.Resource, challenge.RequestURL) {
+		return "", "", fmt.Errorf("resource %q does not match requested URL %q", metadata.Resource, challenge.RequestURL)
+	}
+	if len(metadata.AuthorizationServers) == 0 {
+		return "", "", fmt.Errorf("resource %v lists no authorization servers", metadata.Resource)
+	}
+	server, err := FetchServerMetadata(ctx, client, metadata.AuthorizationServers[0])
+	if err != nil {
+		return "", "", err
+	}
+	return server.TokenEndpoint, metadata.Resource, nil
+}
+
+// matchesResource reports whether requestURL is resource or a path below it on the same origin
+func matchesResource(resource, requestURL string) bool {
+	expect, err := url.Parse(resource)
+	if err != nil || expect.Scheme == "" || expect.Host == "" {
+		return false
+	}
+	actual, err := url.Parse(requestURL)
+	if err != nil || !strings.EqualFold(expect.Scheme, actual.Scheme) || !strings.EqualFold(expect.Host, actual.Host) {
+		return false
+	}
+	prefix := strings.TrimSuffix(expect.EscapedPath(), "/")
+	path := actual.EscapedPath()
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func getJSON(ctx context.Context, client *http.Client, URL string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("failed to fetch %v: status %d", URL, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
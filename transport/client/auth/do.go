@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+)
+
+// Do sends request with source token; on the first 401 it refreshes the token using the response challenge
+// and retries once. The request body must be replayable (GetBody set, as for bytes and strings readers).
+// When the token cannot be refreshed the original 401 response is returned.
+func Do(client *http.Client, source TokenSource, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var rejected *Token
+	if source != nil {
+		token, err := source.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		setToken(req, token)
+		rejected = token
+	}
+	resp, err := client.Do(req)
+	if err != nil || source == nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	retry := req.Clone(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	challenge := ResponseChallenge(resp)
+	token, err := source.Refresh(ctx, rejected, challenge)
+	if err != nil || token == nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	setToken(retry, token)
+	return client.Do(retry)
+}
+
+func setToken(req *http.Request, token *Token) {
+	if token == nil || token.AccessToken == "" {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+}
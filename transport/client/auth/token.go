@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expirySkew refreshes tokens shortly before they expire
+const expirySkew = 30 * time.Second
+
+// Token represents an OAuth 2.0 access token
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	ExpiresAt    time.Time `json:"-"`
+}
+
+// Valid returns true if token has access token and is not about to expire
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.ExpiresAt.IsZero() || time.Now().Add(expirySkew).Before(t.ExpiresAt)
+}
+
+// TokenSource supplies bearer tokens to HTTP transports
+type TokenSource interface {
+	// Token returns current token, obtaining a new one when expired; nil token means the request is sent unauthenticated
+	// (e.g. the token endpoint is discovered from the first challenge).
+	Token(ctx context.Context) (*Token, error)
+	// Refresh obtains a new token after the server rejected the given one (nil for an unauthenticated request)
+	// with challenge; when a valid token other than the rejected one is held (e.g. obtained by a concurrent request)
+	// it is returned instead.
+	Refresh(ctx context.Context, rejected *Token, challenge *Challenge) (*Token, error)
+}
+
+// Config represents OAuth 2.0 client configuration
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	// TokenURL is the token endpoint; when empty it is discovered from the resource_metadata challenge parameter.
+	// Discovery is only used for public clients: a client with ClientSecret must configure TokenURL explicitly.
+	TokenURL string
+	Scopes   []string
+	// Resource is the RFC 8707 resource indicator; when empty the discovered resource is used.
+	Resource   string
+	HTTPClient *http.Client
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// grantSource obtains tokens with an OAuth 2.0 grant; it holds its own copy of the config and keeps discovered
+// endpoint and resource to itself, so a Config shared by several sources is never written to.
+type grantSource struct {
+	config Config
+	// tokenURL and resource are discovered from the latest challenge when config.TokenURL is empty
+	tokenURL     string
+	resource     string
+	grantType    string
+	refreshToken string
+	token        *Token
+	mux          sync.Mutex
+}
+
+// Token returns cached token, obtaining a new one when expired
+func (s *grantSource) Token(ctx context.Context) (*Token, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	if s.endpoint() == "" {
+		return nil, nil
+	}
+	return s.obtain(ctx)
+}
+
+// endpoint returns configured token endpoint, else the discovered one
+func (s *grantSource) endpoint() string {
+	if s.config.TokenURL != "" {
+		return s.config.TokenURL
+	}
+	return s.tokenURL
+}
+
+// resourceIndicator returns configured resource, else the discovered one
+func (s *grantSource) resourceIndicator() string {
+	if s.config.Resource != "" {
+		return s.config.Resource
+	}
+	return s.resource
+}
+
+// Refresh obtains a new token, discovering the token endpoint from challenge when needed. Discovery runs on
+// every challenge, so a token obtained for one resource is dropped rather than reused for a different one.
+func (s *grantSource) Refresh(ctx context.Context, rejected *Token, challenge *Challenge) (*Token, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.config.TokenURL == "" {
+		if s.config.ClientSecret != "" {
+			return nil, fmt.Errorf("oauth2: TokenURL is required for confidential clients, token endpoint discovery is disabled")
+		}
+		tokenURL, resource, err := DiscoverTokenEndpoint(ctx, s.config.httpClient(), challenge)
+		if err != nil {
+			return nil, err
+		}
+		if tokenURL != s.tokenURL || resource != s.resource {
+			s.tokenURL, s.resource = tokenURL, resource
+			s.token = nil
+		}
+	}
+	if s.token.Valid() && (rejected == nil || s.token.AccessToken != rejected.AccessToken) {
+		return s.token, nil
+	}
+	s.token = nil
+	return s.obtain(ctx)
+}
+
+func (s *grantSource) obtain(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {s.grantType}}
+	if s.grantType == "refresh_token" {
+		if s.refreshToken == "" {
+			return nil, fmt.Errorf("oauth2: refresh token is empty")
+		}
+		form.Set("refresh_token", s.refreshToken)
+	}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if resource := s.resourceIndicator(); resource != "" {
+		form.Set("resource", resource)
+	}
+	token, err := requestToken(ctx, &s.config, s.endpoint(), form)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	s.token = token
+	return token, nil
+}
+
+// requestToken posts form to the tokenURL endpoint using client_secret_basic authentication
+func requestToken(ctx context.Context, config *Config, tokenURL string, form url.Values) (*Token, error) {
+	if config.ClientSecret == "" && config.ClientID != "" {
+		form.Set("client_id", config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+	resp, err := config.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	response := &struct {
+		Token
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	_ = json.Unmarshal(body, response)
+	if resp.StatusCode != http.StatusOK || response.Error != "" {
+		if response.Error == "" {
+			return nil, fmt.Errorf("oauth2: token endpoint status %d: %s", resp.StatusCode, body)
+		}
+		return nil, fmt.Errorf("oauth2: %v: %v", response.Error, response.ErrorDescription)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response missing access_token")
+	}
+	token := response.Token
+	if response.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return &token, nil
+}
+
+// clone returns a copy of config that does not share the scopes slice
+func (c *Config) clone() Config {
+	ret := *c
+	ret.Scopes = append([]string(nil), c.Scopes...)
+	return ret
+}
+
+// ClientCredentials returns token source using the client_credentials grant; config is copied
+func ClientCredentials(config *Config) TokenSource {
+	return &grantSource{config: config.clone(), grantType: "client_credentials"}
+}
+
+// RefreshToken returns token source using the refresh_token grant starting from token (its access token is used until it expires); config is copied
+func RefreshToken(config *Config, token *Token) TokenSource {
+	ret := &grantSource{config: config.clone(), grantType: "refresh_token", token: token}
+	if token != nil {
+		ret.refreshToken = token.RefreshToken
+	}
+	return ret
+}
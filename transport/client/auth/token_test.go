@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenge(t *testing.T) {
+	var testCases = []struct {
+		description string
+		header      string
+		expect      *Challenge
+	}{
+		{description: "empty", header: "", expect: nil},
+		{description: "realm only", header: `Bearer realm="mcp"`, expect: &Challenge{Scheme: "Bearer", Realm: "mcp", Params: map[string]string{"realm": "mcp"}}},
+		{
+			description: "resource metadata and error",
+			header:      `Bearer realm="mcp", resource_metadata="https://api.example.com/.well-known/oauth-protected-resource/mcp", error="invalid_token", error_description="token \"x\" expired"`,
+			expect: &Challenge{Scheme: "Bearer", Realm: "mcp", Error: "invalid_token", ErrorDescription: `token "x" expired`,
+				ResourceMetadata: "https://api.example.com/.well-known/oauth-protected-resource/mcp",
+				Params: map[string]string{"realm": "mcp", "error": "invalid_token", "error_description": `token "x" expired`,
+					"resource_metadata": "https://api.example.com/.well-known/oauth-protected-resource/mcp"}},
+		},
+		{description: "token values", header: `Bearer scope=tools, error=insufficient_scope`, expect: &Challenge{Scheme: "Bearer", Error: "insufficient_scope", Scope: "tools", Params: map[string]string{"scope": "tools", "error": "insufficient_scope"}}},
+	}
+	for _, testCase := range testCases {
+		assert.EqualValues(t, testCase.expect, ParseChallenge(testCase.header), testCase.description)
+	}
+}
+
+// authorizationServer issues sequential tokens and serves RFC 8414 and RFC 9728 metadata
+func authorizationServer(t *testing.T, issued *int32) *httptest.Server {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&ServerMetadata{Issuer: server.URL, TokenEndpoint: server.URL + "/token"})
+	})
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&ResourceMetadata{Resource: server.URL + "/mcp", AuthorizationServers: []string{server.URL}})
+	})
+	mux.HandleFunc("/.well-known/oauth-protected-resource/other", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&ResourceMetadata{Resource: server.URL + "/other", AuthorizationServers: []string{server.URL}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		clientID, secret, ok := r.BasicAuth()
+		if !ok {
+			clientID, secret = r.Form.Get("client_id"), "public"
+		}
+		if !(clientID == "svc" && secret == "secret") && !(clientID == "app" && secret == "public") {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+		switch r.Form.Get("grant_type") {
+		case "client_credentials":
+		case "refresh_token":
+			if !strings.HasPrefix(r.Form.Get("refresh_token"), "refresh-") {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := atomic.AddInt32(issued, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("access-%d|%s", n, r.Form.Get("resource")),
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+		})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTokenSource(t *testing.T) {
+	var issued int32
+	server := authorizationServer(t, &issued)
+	ctx := context.Background()
+
+	source := ClientCredentials(&Config{ClientID: "svc", ClientSecret: "secret", TokenURL: server.URL + "/token"})
+	token, err := source.Token(ctx)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "access-1|", token.AccessToken)
+	assert.True(t, token.Valid())
+	cached, _ := source.Token(ctx)
+	assert.Equal(t, token.AccessToken, cached.AccessToken, "valid token is reused")
+	refreshed, err := source.Refresh(ctx, token, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "access-2|", refreshed.AccessToken)
+
+	stale := &Token{AccessToken: "stale", RefreshToken: "refresh-0"}
+	source = RefreshToken(&Config{ClientID: "svc", ClientSecret: "secret", TokenURL: server.URL + "/token"}, stale)
+	refreshed, err = source.Refresh(ctx, stale, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "refresh-3", refreshed.RefreshToken, "refresh token rotates")
+
+	_, err = ClientCredentials(&Config{ClientID: "svc", ClientSecret: "wrong", TokenURL: server.URL + "/token"}).Token(ctx)
+	assert.EqualError(t, err, "oauth2: invalid_client: bad credentials")
+}
+
+func TestTokenSource_Discovery(t *testing.T) {
+	var issued int32
+	server := authorizationServer(t, &issued)
+	ctx := context.Background()
+	metadataURL := server.URL + "/.well-known/oauth-protected-resource/mcp"
+
+	var testCases = []struct {
+		description string
+		config      *Config
+		challenge   *Challenge
+		expectToken string
+		expectErr   string
+	}{
+		{
+			description: "public client discovers token endpoint",
+			config:      &Config{ClientID: "app"},
+			challenge:   &Challenge{ResourceMetadata: metadataURL, RequestURL: server.URL + "/mcp"},
+			expectToken: "access-1|" + server.URL + "/mcp",
+		},
+		{
+			description: "resource does not match requested URL",
+			config:      &Config{ClientID: "app"},
+			challenge:   &Challenge{ResourceMetadata: metadataURL, RequestURL: "https://other.example.com/mcp"},
+			expectErr:   `resource "` + server.URL + `/mcp" does not match requested URL "https://other.example.com/mcp"`,
+		},
+		{
+			description: "resource path is not a prefix of requested URL",
+			config:      &Config{ClientID: "app"},
+			challenge:   &Challenge{ResourceMetadata: metadataURL, RequestURL: server.URL + "/mcp-admin"},
+			expectErr:   `resource "` + server.URL + `/mcp" does not match requested URL "` + server.URL + `/mcp-admin"`,
+		},
+		{
+			description: "request URL unknown",
+			config:      &Config{ClientID: "app"},
+			challenge:   &Challenge{ResourceMetadata: metadataURL},
+			expectErr:   "challenge request URL is unknown",
+		},
+		{
+			description: "confidential client never discovers",
+			config:      &Config{ClientID: "svc", ClientSecret: "secret"},
+			challenge:   &Challenge{ResourceMetadata: metadataURL, RequestURL: server.URL + "/mcp"},
+			expectErr:   "oauth2: TokenURL is required for confidential clients, token endpoint discovery is disabled",
+		},
+	}
+	for _, testCase := range testCases {
+		source := ClientCredentials(testCase.config)
+		token, err := source.Token(ctx)
+		assert.Nil(t, err, testCase.description)
+		assert.Nil(t, token, "token endpoint is unknown until the first challenge: "+testCase.description)
+
+		token, err = source.Refresh(ctx, nil, testCase.challenge)
+		if testCase.expectErr != "" {
+			assert.EqualError(t, err, testCase.expectErr, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expectToken, token.AccessToken, testCase.description)
+	}
+}
+
+func TestTokenSource_SharedConfig(t *testing.T) {
+	var issued int32
+	server := authorizationServer(t, &issued)
+	ctx := context.Background()
+	mcp := &Challenge{ResourceMetadata: server.URL + "/.well-known/oauth-protected-resource/mcp", RequestURL: server.URL + "/mcp"}
+	other := &Challenge{ResourceMetadata: server.URL + "/.well-known/oauth-protected-resource/other", RequestURL: server.URL + "/other"}
+
+	config := &Config{ClientID: "app"}
+	first, second := ClientCredentials(config), ClientCredentials(config)
+	token, err := first.Refresh(ctx, nil, mcp)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.HasSuffix(token.AccessToken, "|"+server.URL+"/mcp"), token.AccessToken)
+	token, err = second.Refresh(ctx, nil, other)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.HasSuffix(token.AccessToken, "|"+server.URL+"/other"), token.AccessToken)
+	assert.Equal(t, &Config{ClientID: "app"}, config, "discovered values are not written to the shared config")
+
+	token, err = first.Refresh(ctx, nil, other)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, strings.HasSuffix(token.AccessToken, "|"+server.URL+"/other"), "token for another resource is not reused: "+token.AccessToken)
+}
+
+func TestFetchServerMetadata_IssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&ServerMetadata{Issuer: "https://attacker.example.com", TokenEndpoint: "https://attacker.example.com/token"})
+	}))
+	defer server.Close()
+	_, err := FetchServerMetadata(context.Background(), http.DefaultClient, server.URL)
+	assert.EqualError(t, err, `authorization server metadata issuer "https://attacker.example.com" does not match "`+server.URL+`"`)
+}
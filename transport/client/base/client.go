@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -24,6 +25,12 @@ type Client struct {
 	Interceptor  transport.Interceptor // Interceptor for request/response
 	RequestIdSeq uint64
 	err          error
+	// progress holds per-call progress handlers keyed by progress token
+	progress sync.Map
+	// chunks holds per-call chunk handlers keyed by request id
+	chunks sync.Map
+	// jobs holds job completion watchers keyed by job id
+	jobs sync.Map
 }
 
 // LastRequestID returns the most recently generated request id without mutating the underlying sequence.
@@ -52,6 +59,26 @@ func (c *Client) Send(ctx context.Context, request *jsonrpc.Request) (*jsonrpc.R
 	if request.Id == nil {
 		request.Id = c.NextRequestID()
 	}
+	if handler, ok := transport.ProgressHandlerFromContext(ctx); ok {
+		token, ok := transport.ProgressToken(request)
+		if !ok {
+			token = request.Id
+			if err := transport.SetProgressToken(request, token); err != nil {
+				return nil, fmt.Errorf("failed to set progress token: %w", err)
+			}
+		}
+		key := progressKey(token)
+		c.progress.Store(key, handler)
+		defer c.progress.Delete(key)
+	}
+	if handler, ok := transport.ChunkHandlerFromContext(ctx); ok {
+		if err := transport.SetStreamRequested(request); err != nil {
+			return nil, fmt.Errorf("failed to request stream: %w", err)
+		}
+		key := progressKey(request.Id)
+		c.chunks.Store(key, handler)
+		defer c.chunks.Delete(key)
+	}
 	trip, err := c.send(ctx, request)
 	if err != nil {
 		return nil, err // send error
@@ -153,9 +180,67 @@ func (c *Client) handleOnNotification(ctx context.Context, data []byte, message
 			c.Logger.Errorf("failed to parse notification: %v, %s", err, data)
 		}
 	}
+	if notification.Method == transport.ProgressNotification {
+		c.handleProgress(notification)
+	}
+	if notification.Method == transport.ChunkNotification {
+		c.handleChunk(notification)
+	}
+	if notification.Method == transport.JobCompletedNotification {
+		c.handleJobCompleted(notification)
+	}
 	c.Handler.OnNotification(ctx, notification)
 }
 
+// handleProgress dispatches progress notification to the per-call handler
+func (c *Client) handleProgress(notification *jsonrpc.Notification) {
+	params := &transport.ProgressParams{}
+	if err := json.Unmarshal(notification.Params, params); err != nil || params.ProgressToken == nil {
+		return
+	}
+	if handler, ok := c.progress.Load(progressKey(params.ProgressToken)); ok {
+		handler.(transport.ProgressHandler)(params)
+	}
+}
+
+// handleChunk dispatches chunk notification to the per-call handler
+func (c *Client) handleChunk(notification *jsonrpc.Notification) {
+	chunk := &transport.ChunkParams{}
+	if err := json.Unmarshal(notification.Params, chunk); err != nil || chunk.RequestId == nil {
+		return
+	}
+	if handler, ok := c.chunks.Load(progressKey(chunk.RequestId)); ok {
+		handler.(transport.ChunkHandler)(chunk)
+	}
+}
+
+// WatchJob returns channel receiving the job once its completion notification arrives
+func (c *Client) WatchJob(id string) (<-chan *transport.Job, func()) {
+	completed := make(chan *transport.Job, 1)
+	c.jobs.Store(id, completed)
+	return completed, func() { c.jobs.CompareAndDelete(id, completed) }
+}
+
+// handleJobCompleted dispatches job completion notification to its watcher
+func (c *Client) handleJobCompleted(notification *jsonrpc.Notification) {
+	job := &transport.Job{}
+	if err := json.Unmarshal(notification.Params, job); err != nil || job.ID == "" {
+		return
+	}
+	if value, ok := c.jobs.LoadAndDelete(job.ID); ok {
+		value.(chan *transport.Job) <- job
+	}
+}
+
+// progressKey normalizes progress token to its JSON encoding, so numeric tokens match after a JSON round trip
+// (int 1 and float64 1 share a key) while numeric and string tokens stay distinct (1 and "1" do not)
+func progressKey(token any) string {
+	if data, err := json.Marshal(token); err == nil {
+		return string(data)
+	}
+	return fmt.Sprintf("%T:%v", token, token)
+}
+
 func (c *Client) send(ctx context.Context, request *jsonrpc.Request) (*transport.RoundTrip, error) {
 	if c.err != nil {
 		return nil, c.err
@@ -0,0 +1,27 @@
+package base
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressKey(t *testing.T) {
+	var decoded any
+	_ = json.Unmarshal([]byte(`1`), &decoded)
+	var testCases = []struct {
+		description string
+		left        any
+		right       any
+		expectSame  bool
+	}{
+		{description: "int matches number after json round trip", left: 1, right: decoded, expectSame: true},
+		{description: "number and string differ", left: 1, right: "1"},
+		{description: "decoded number and string differ", left: decoded, right: "1"},
+		{description: "same string", left: "abc", right: "abc", expectSame: true},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectSame, progressKey(testCase.left) == progressKey(testCase.right), testCase.description)
+	}
+}
@@ -0,0 +1,27 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/viant/jsonrpc/transport"
+)
+
+// HandleReplayGap parses a replay-gap event payload and passes it to onGap.
+// When onGap is nil the lost range is logged instead.
+func (c *Client) HandleReplayGap(ctx context.Context, data string, onGap func(ctx context.Context, gap *transport.ReplayGap)) {
+	gap := &transport.ReplayGap{}
+	if err := json.Unmarshal([]byte(data), gap); err != nil {
+		if c.Logger != nil {
+			c.Logger.Errorf("failed to parse replay gap: %v", err)
+		}
+		return
+	}
+	if onGap == nil {
+		if c.Logger != nil {
+			c.Logger.Errorf("replay gap: events after %d were lost, oldest available: %d", gap.LastEventID, gap.OldestAvailableID)
+		}
+		return
+	}
+	onGap(ctx, gap)
+}
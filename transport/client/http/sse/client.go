@@ -7,6 +7,7 @@ import (
 	"github.com/viant/afs/url"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
 	"github.com/viant/jsonrpc/transport/client/base"
 	"io"
 	"net/http"
@@ -39,6 +40,12 @@ type Client struct {
 	// session id on reconnect GET requests. Defaults to "Mcp-Session-Id" to
 	// align with server default, but can be overridden via option.
 	streamSessionParamName string
+
+	// onReplayGap is notified when server could not replay all messages after Last-Event-ID.
+	onReplayGap func(ctx context.Context, gap *transport.ReplayGap)
+
+	// tokenSource supplies bearer tokens; a rejected token is refreshed and the request retried once.
+	tokenSource auth.TokenSource
 }
 
 // Close stops the SSE listener and prevents further reconnect attempts.
@@ -73,7 +80,8 @@ func (c *Client) sessionContext(ctx context.Context) context.Context {
 	if c.sessionID == "" {
 		return ctx
 	}
-	return context.WithValue(ctx, jsonrpc.SessionKey, c.sessionID)
+	ctx = context.WithValue(ctx, jsonrpc.SessionKey, c.sessionID)
+	return jsonrpc.WithRequestInfo(ctx, &jsonrpc.RequestInfo{Transport: jsonrpc.TransportSSE, SessionID: c.sessionID})
 }
 
 func (c *Client) start(ctx context.Context) error {
@@ -81,7 +89,7 @@ func (c *Client) start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	resp, err := c.transport.sseClient.Do(req)
+	resp, err := auth.Do(c.transport.sseClient, c.tokenSource, req)
 	if err != nil {
 		return fmt.Errorf("failed to connect to SSE stream: %w", err)
 	}
@@ -113,6 +121,16 @@ func (c *Client) Send(ctx context.Context, request *jsonrpc.Request) (*jsonrpc.R
 	return c.base.Send(c.sessionContext(ctx), request)
 }
 
+// SendStream sends request asking the server to stream its result as chunks followed by the final response.
+func (c *Client) SendStream(ctx context.Context, request *jsonrpc.Request) *transport.Stream {
+	return transport.SendStream(ctx, c, request, 16)
+}
+
+// WatchJob returns channel receiving the job once its completion notification arrives.
+func (c *Client) WatchJob(id string) (<-chan *transport.Job, func()) {
+	return c.base.WatchJob(id)
+}
+
 // SessionID returns the current session id if known.
 func (c *Client) SessionID() string { return c.sessionID }
 
@@ -247,6 +265,8 @@ func (c *Client) listenForMessages(ctx context.Context, reader *bufio.Reader) {
 			switch event.Event {
 			case "message":
 				c.base.HandleMessage(c.sessionContext(ctx), []byte(event.Data))
+			case transport.ReplayGapEvent:
+				c.base.HandleReplayGap(c.sessionContext(ctx), event.Data, c.onReplayGap)
 			default:
 				continue
 			}
@@ -285,7 +305,7 @@ func (c *Client) reconnect(ctx context.Context) (*bufio.Reader, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.transport.sseClient.Do(req)
+	resp, err := auth.Do(c.transport.sseClient, c.tokenSource, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to SSE stream: %w", err)
 	}
@@ -1,8 +1,10 @@
 package sse
 
 import (
+	"context"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
 	"net/http"
 	"time"
 )
@@ -114,3 +116,19 @@ func WithStreamSessionParamName(name string) Option {
 		}
 	}
 }
+
+// WithOnReplayGap sets a callback invoked when the server signals that messages after the
+// client's Last-Event-ID were lost (replay buffer overflow), so the application can resync state.
+func WithOnReplayGap(fn func(ctx context.Context, gap *transport.ReplayGap)) Option {
+	return func(c *Client) {
+		c.onReplayGap = fn
+	}
+}
+
+// WithTokenSource attaches bearer tokens from source to every request; on the first 401 the token is refreshed
+// (discovering the authorization server from WWW-Authenticate when needed) and the request or stream reconnect retried once.
+func WithTokenSource(source auth.TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = source
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/viant/afs/url"
 	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport/client/auth"
 )
 
 type Transport struct {
@@ -36,7 +37,7 @@ func (t *Transport) SendData(ctx context.Context, data []byte) error {
 	for k, v := range t.headers {
 		req.Header[k] = v
 	}
-	resp, err := t.messageClient.Do(req)
+	resp, err := auth.Do(t.messageClient, t.client.tokenSource, req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
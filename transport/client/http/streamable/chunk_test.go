@@ -0,0 +1,110 @@
+package streamable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	server "github.com/viant/jsonrpc/transport/server/http/streamable"
+)
+
+type chunkHandler struct{}
+
+func (h *chunkHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	for i := 1; i <= 5; i++ {
+		_ = transport.WriteChunk(ctx, map[string]int{"hit": i})
+	}
+	response.Result = []byte(`{"total":5}`)
+}
+
+func (h *chunkHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestClient_SendStream(t *testing.T) {
+	handler := server.New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &chunkHandler{} },
+		server.WithURI("/mcp"), server.WithCleanupInterval(0))
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := New(context.Background(), srv.URL+"/mcp")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream := client.SendStream(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Method: "search", Params: []byte(`{"q":"error"}`)})
+	var hits []int
+	for chunk := range stream.Chunks() {
+		hit := struct {
+			Hit int `json:"hit"`
+		}{}
+		assert.Nil(t, json.Unmarshal(chunk.Data, &hit))
+		assert.Equal(t, len(hits)+1, chunk.Seq)
+		hits = append(hits, hit.Hit)
+	}
+	response, err := stream.Response()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, hits)
+	assert.JSONEq(t, `{"total":5}`, string(response.Result))
+}
+
+type bulkChunkHandler struct{}
+
+func (h *bulkChunkHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	for i := 1; i <= 64; i++ {
+		_ = transport.WriteChunk(ctx, map[string]int{"hit": i})
+	}
+	response.Result = []byte(`{"total":64}`)
+}
+
+func (h *bulkChunkHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestClient_SendStream_SlowConsumer(t *testing.T) {
+	handler := server.New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &bulkChunkHandler{} },
+		server.WithURI("/mcp"), server.WithCleanupInterval(0))
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := New(context.Background(), srv.URL+"/mcp")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream := client.SendStream(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Method: "search", Params: []byte(`{}`)})
+	// chunks exceed the channel buffer and are not drained: the read loop must still deliver the response
+	response, err := stream.Response()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{"total":64}`, string(response.Result))
+	count := 0
+	for chunk := range stream.Chunks() {
+		count++
+		assert.Equal(t, count, chunk.Seq)
+	}
+	assert.Equal(t, 64, count)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/viant/afs/url"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
 	"github.com/viant/jsonrpc/transport/client/base"
 	"net/http/cookiejar"
 	"sync"
@@ -31,6 +32,8 @@ type Client struct {
 	httpClient       *http.Client
 	handshakeTimeout time.Duration
 
+	// sessionID is guarded by sessionMu; it is set from POST responses while stream and handlers read it
+	sessionMu sync.RWMutex
 	sessionID string
 
 	lastIDGet  uint64
@@ -46,6 +49,9 @@ type Client struct {
 	// on all HTTP requests (POST/GET) made by this client.
 	protocolVersion string
 
+	// tokenSource supplies bearer tokens; a rejected token is refreshed and the request retried once.
+	tokenSource auth.TokenSource
+
 	// streaming control
 	streamMu     sync.Mutex
 	streamActive bool
@@ -57,6 +63,9 @@ type Client struct {
 	done         chan struct{}
 	streamCtx    context.Context
 	streamCancel context.CancelFunc
+
+	// onReplayGap is notified when server could not replay all messages after Last-Event-ID.
+	onReplayGap func(ctx context.Context, gap *transport.ReplayGap)
 }
 
 // Close terminates the background SSE stream goroutine started by
@@ -81,10 +90,12 @@ func (c *Client) Close() error {
 // sessionContext returns a context enriched with the current MCP session id. If
 // no session id has been established yet it returns the original context.
 func (c *Client) sessionContext(ctx context.Context) context.Context {
-	if c.sessionID == "" {
+	sessionID := c.SessionID()
+	if sessionID == "" {
 		return ctx
 	}
-	return context.WithValue(ctx, jsonrpc.SessionKey, c.sessionID)
+	ctx = context.WithValue(ctx, jsonrpc.SessionKey, sessionID)
+	return jsonrpc.WithRequestInfo(ctx, &jsonrpc.RequestInfo{Transport: jsonrpc.TransportStreamable, SessionID: sessionID})
 }
 
 // Notify sends JSON-RPC notification.
@@ -97,8 +108,28 @@ func (c *Client) Send(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.Respons
 	return c.base.Send(c.sessionContext(ctx), r)
 }
 
+// SendStream sends request asking the server to stream its result as chunks followed by the final response.
+func (c *Client) SendStream(ctx context.Context, request *jsonrpc.Request) *transport.Stream {
+	return transport.SendStream(ctx, c, request, 16)
+}
+
+// WatchJob returns channel receiving the job once its completion notification arrives.
+func (c *Client) WatchJob(id string) (<-chan *transport.Job, func()) {
+	return c.base.WatchJob(id)
+}
+
 // SessionID returns the currently configured or negotiated session id.
-func (c *Client) SessionID() string { return c.sessionID }
+func (c *Client) SessionID() string {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
+	return c.sessionID
+}
+
+func (c *Client) setSessionID(id string) {
+	c.sessionMu.Lock()
+	c.sessionID = id
+	c.sessionMu.Unlock()
+}
 
 func (c *Client) openStream(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpointURL, nil)
@@ -106,7 +137,7 @@ func (c *Client) openStream(ctx context.Context) error {
 		return err
 	}
 	req.Header.Set("Accept", sseMime)
-	req.Header.Set(c.sessionHeaderName, c.sessionID)
+	req.Header.Set(c.sessionHeaderName, c.SessionID())
 	if c.protocolVersion != "" {
 		req.Header.Set("MCP-Protocol-Version", c.protocolVersion)
 	}
@@ -114,7 +145,7 @@ func (c *Client) openStream(ctx context.Context) error {
 		req.Header.Set("Last-Event-ID", fmt.Sprintf("%d", c.lastIDGet))
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := auth.Do(c.httpClient, c.tokenSource, req)
 	if err != nil {
 		return fmt.Errorf("failed to open stream: %w", err)
 	}
@@ -151,6 +182,10 @@ func (c *Client) consumeSSEGet(ctx context.Context, reader *bufio.Reader) {
 				c.lastIDGet = v
 			}
 		}
+		if evt.Event == transport.ReplayGapEvent {
+			c.base.HandleReplayGap(c.sessionContext(ctx), evt.Data, c.onReplayGap)
+			continue
+		}
 		if evt.Event != "message" || strings.TrimSpace(evt.Data) == "" {
 			continue
 		}
@@ -173,6 +208,10 @@ func (c *Client) consumeSSEPost(ctx context.Context, reader *bufio.Reader) {
 				c.lastIDPost = v
 			}
 		}
+		if evt.Event == transport.ReplayGapEvent {
+			c.base.HandleReplayGap(c.sessionContext(ctx), evt.Data, c.onReplayGap)
+			continue
+		}
 		if evt.Event != "message" || strings.TrimSpace(evt.Data) == "" {
 			continue
 		}
@@ -251,7 +290,7 @@ func (c *Client) runStream() {
 		default:
 		}
 		// wait until session id is available
-		if c.sessionID == "" {
+		if c.SessionID() == "" {
 			select {
 			case <-c.done:
 				return
@@ -0,0 +1,33 @@
+package streamable
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	clientbase "github.com/viant/jsonrpc/transport/client/base"
+)
+
+func TestClient_ReplayGapCallback(t *testing.T) {
+	var actual *transport.ReplayGap
+	client := &Client{
+		sessionID: "session-1",
+		base:      &clientbase.Client{Handler: &clientbase.Handler{}, RoundTrips: transport.NewRoundTrips(1), Logger: jsonrpc.DefaultLogger},
+	}
+	WithOnReplayGap(func(ctx context.Context, gap *transport.ReplayGap) {
+		actual = gap
+	})(client)
+
+	stream := "event: replay-gap\ndata: {\"lastEventId\":3,\"oldestAvailableId\":9}\n\n" +
+		"id: 9\nevent: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"ping\"}\n\n"
+	client.consumeSSEGet(context.Background(), bufio.NewReader(strings.NewReader(stream)))
+
+	if assert.NotNil(t, actual) {
+		assert.EqualValues(t, &transport.ReplayGap{LastEventID: 3, OldestAvailableID: 9}, actual)
+	}
+	assert.EqualValues(t, 9, client.lastIDGet)
+}
@@ -0,0 +1,75 @@
+package streamable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	server "github.com/viant/jsonrpc/transport/server/http/streamable"
+)
+
+type jobHandler struct {
+	server *server.Handler
+}
+
+func (h *jobHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	jobs := h.server.Jobs()
+	if jobs.HandleRequest(ctx, request, response) {
+		return
+	}
+	if request.Method == "index" {
+		jobs.Respond(ctx, request, response, func(ctx context.Context) (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return map[string]int{"files": 42}, nil
+		})
+		return
+	}
+	response.Result = []byte(`{}`)
+}
+
+func (h *jobHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestClient_AwaitJob(t *testing.T) {
+	var handler *server.Handler
+	handler = server.New(func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &jobHandler{server: handler}
+	},
+		server.WithURI("/mcp"), server.WithCleanupInterval(0))
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := New(context.Background(), srv.URL+"/mcp")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	response, err := client.Send(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Method: "index"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	handle := &transport.JobHandle{}
+	assert.Nil(t, json.Unmarshal(response.Result, handle))
+	assert.NotEmpty(t, handle.ID)
+
+	job, err := transport.AwaitJob(ctx, client, handle.ID, 20*time.Millisecond)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, transport.JobCompleted, job.State)
+	assert.JSONEq(t, `{"files":42}`, string(job.Result))
+}
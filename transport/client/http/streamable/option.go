@@ -1,8 +1,10 @@
 package streamable
 
 import (
+	"context"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
 	"net/http"
 	"time"
 )
@@ -82,7 +84,7 @@ func WithSessionID(id string) Option {
 		if id == "" {
 			return
 		}
-		c.sessionID = id
+		c.setSessionID(id)
 		if c.transport != nil && c.transport.headers != nil {
 			// Ensure POSTs include the session header immediately
 			if c.sessionHeaderName == "" {
@@ -94,3 +96,19 @@ func WithSessionID(id string) Option {
 		c.ensureStream()
 	}
 }
+
+// WithOnReplayGap sets a callback invoked when the server signals that messages after the
+// client's Last-Event-ID were lost (replay buffer overflow), so the application can resync state.
+func WithOnReplayGap(fn func(ctx context.Context, gap *transport.ReplayGap)) Option {
+	return func(c *Client) {
+		c.onReplayGap = fn
+	}
+}
+
+// WithTokenSource attaches bearer tokens from source to every request; on the first 401 the token is refreshed
+// (discovering the authorization server from WWW-Authenticate when needed) and the request or stream reconnect retried once.
+func WithTokenSource(source auth.TokenSource) Option {
+	return func(c *Client) {
+		c.tokenSource = source
+	}
+}
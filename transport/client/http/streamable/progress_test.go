@@ -0,0 +1,67 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	server "github.com/viant/jsonrpc/transport/server/http/streamable"
+)
+
+type progressHandler struct{}
+
+func (h *progressHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	for i := 1; i <= 3; i++ {
+		_ = transport.Progress(ctx, float64(i), 3, "step")
+	}
+	response.Result = []byte(`{"done":true}`)
+}
+
+func (h *progressHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestClient_Progress(t *testing.T) {
+	handler := server.New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &progressHandler{} },
+		server.WithURI("/mcp"), server.WithCleanupInterval(0))
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := New(context.Background(), srv.URL+"/mcp")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	var mux2 sync.Mutex
+	var received []*transport.ProgressParams
+	ctx := transport.WithProgressHandler(context.Background(), func(params *transport.ProgressParams) {
+		mux2.Lock()
+		defer mux2.Unlock()
+		received = append(received, params)
+	})
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	response, err := client.Send(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Method: "tools/call", Params: []byte(`{"name":"index"}`)})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{"done":true}`, string(response.Result))
+	mux2.Lock()
+	defer mux2.Unlock()
+	if assert.Len(t, received, 3) {
+		assert.EqualValues(t, 3, received[2].Progress)
+		assert.EqualValues(t, 3, received[2].Total)
+		assert.Equal(t, "step", received[2].Message)
+	}
+}
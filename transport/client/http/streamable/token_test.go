@@ -0,0 +1,95 @@
+package streamable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
+	serverauth "github.com/viant/jsonrpc/transport/server/auth"
+	server "github.com/viant/jsonrpc/transport/server/http/streamable"
+)
+
+type echoHandler struct{}
+
+func (h *echoHandler) Serve(_ context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	response.Result = []byte(`{}`)
+}
+
+func (h *echoHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestClient_TokenSource(t *testing.T) {
+	var mux sync.Mutex
+	issued := 0
+	valid := map[string]bool{}
+	metadata := &serverauth.ProtectedResourceMetadata{}
+	verifier := serverauth.TokenVerifierFunc(func(ctx context.Context, token string) (*serverauth.Principal, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if !valid[token] {
+			return nil, serverauth.ErrTokenExpired
+		}
+		return &serverauth.Principal{Subject: "svc", Audience: []string{metadata.Resource}}, nil
+	})
+	handler := server.New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &echoHandler{} },
+		server.WithURI("/mcp"),
+		server.WithCleanupInterval(0),
+		server.WithBearerAuth(serverauth.NewBearerAuthenticator(verifier)),
+		server.WithProtectedResourceMetadata(metadata),
+	)
+	routes := http.NewServeMux()
+	routes.Handle("/mcp", handler)
+	routes.Handle(serverauth.WellKnownProtectedResourcePath+"/", handler)
+	var srv *httptest.Server
+	routes.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&auth.ServerMetadata{Issuer: srv.URL, TokenEndpoint: srv.URL + "/token"})
+	})
+	routes.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mux.Lock()
+		issued++
+		token := fmt.Sprintf("token-%d", issued)
+		valid[token] = r.Form.Get("resource") == metadata.Resource
+		mux.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+	})
+	srv = httptest.NewTLSServer(routes)
+	defer srv.Close()
+	metadata.Resource = srv.URL + "/mcp"
+	metadata.AuthorizationServers = []string{srv.URL}
+
+	source := auth.ClientCredentials(&auth.Config{ClientID: "app", HTTPClient: srv.Client()})
+	client, err := New(context.Background(), srv.URL+"/mcp", WithHTTPClient(srv.Client()), WithTokenSource(source))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	if !assert.Nil(t, err, "token endpoint discovered from the first challenge") {
+		return
+	}
+
+	mux.Lock()
+	valid["token-1"] = false // server-side revocation
+	mux.Unlock()
+	_, err = client.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "ping"})
+	assert.Nil(t, err, "rejected token is refreshed and the request retried")
+	mux.Lock()
+	assert.Equal(t, 2, issued)
+	mux.Unlock()
+
+	noToken, err := New(context.Background(), srv.URL+"/mcp", WithHTTPClient(srv.Client()))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer noToken.Close()
+	_, err = noToken.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "initialize", Params: []byte(`{}`)})
+	assert.True(t, jsonrpc.IsUnauthorized(err))
+}
@@ -11,6 +11,8 @@ import (
 	"sync"
 
 	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/client/auth"
 )
 
 // Transport implements client side sender for the streaming HTTP transport. It
@@ -56,12 +58,18 @@ func (t *Transport) SendData(ctx context.Context, data []byte) error {
 	// carries server-initiated requests such as sampling, while the final
 	// response for this request is delivered on this POST body.
 	req.Header.Set("Accept", "application/json")
+	_, progress := transport.ProgressHandlerFromContext(ctx)
+	_, chunks := transport.ChunkHandlerFromContext(ctx)
+	if progress || chunks {
+		// let the server stream progress and chunk notifications ahead of the response
+		req.Header.Set("Accept", "application/json, "+sseMime)
+	}
 	for k, v := range t.headers {
 		req.Header[k] = append([]string(nil), v...)
 	}
 	unlock()
 
-	resp, err := t.client.Do(req)
+	resp, err := auth.Do(t.client, t.c.tokenSource, req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -81,7 +89,7 @@ func (t *Transport) SendData(ctx context.Context, data []byte) error {
 	if sessionID := resp.Header.Get(t.c.sessionHeaderName); sessionID != "" {
 		// Update known session id and ensure the GET stream is running
 		t.Lock()
-		t.c.sessionID = sessionID
+		t.c.setSessionID(sessionID)
 		// Ensure subsequent message POSTs include the session id header
 		t.headers.Set(t.c.sessionHeaderName, sessionID)
 		t.Unlock()
@@ -89,7 +97,7 @@ func (t *Transport) SendData(ctx context.Context, data []byte) error {
 		t.c.ensureStream()
 	}
 
-	if t.c.sessionID == "" {
+	if t.c.SessionID() == "" {
 		_ = resp.Body.Close()
 		return fmt.Errorf("handshake missing %s header", t.c.sessionHeaderName)
 	}
@@ -35,7 +35,8 @@ func (c *Client) sessionContext(ctx context.Context) context.Context {
 	if c.sessionID == "" {
 		return ctx
 	}
-	return context.WithValue(ctx, jsonrpc.SessionKey, c.sessionID)
+	ctx = context.WithValue(ctx, jsonrpc.SessionKey, c.sessionID)
+	return jsonrpc.WithRequestInfo(ctx, &jsonrpc.RequestInfo{Transport: jsonrpc.TransportStdio, SessionID: c.sessionID})
 }
 
 func (c *Client) start(ctx context.Context) error {
@@ -94,6 +95,16 @@ func (c *Client) Send(ctx context.Context, request *jsonrpc.Request) (*jsonrpc.R
 	return c.base.Send(c.sessionContext(ctx), request)
 }
 
+// SendStream sends request asking the server to stream its result as chunks followed by the final response.
+func (c *Client) SendStream(ctx context.Context, request *jsonrpc.Request) *transport2.Stream {
+	return transport2.SendStream(ctx, c, request, 16)
+}
+
+// WatchJob returns channel receiving the job once its completion notification arrives.
+func (c *Client) WatchJob(id string) (<-chan *transport2.Job, func()) {
+	return c.base.WatchJob(id)
+}
+
 func (c *Client) ensureSSHConfig(ctx context.Context) error {
 	if c.sshConfig != nil || c.host == "" {
 		return nil
@@ -0,0 +1,13 @@
+package transport
+
+// ReplayGapEvent is the SSE event name signaling that replay after Last-Event-ID is incomplete.
+const ReplayGapEvent = "replay-gap"
+
+// ReplayGap describes messages lost because the server replay buffer overflowed
+// before a reconnecting client could resume from its Last-Event-ID.
+type ReplayGap struct {
+	// LastEventID is the Last-Event-ID presented by the client.
+	LastEventID uint64 `json:"lastEventId"`
+	// OldestAvailableID is the oldest event id still available for replay.
+	OldestAvailableID uint64 `json:"oldestAvailableId"`
+}
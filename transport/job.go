@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/viant/jsonrpc"
+)
+
+const (
+	// JobStatusMethod returns job state: params ["id"] or {"jobId":"..."}
+	JobStatusMethod = "job/status"
+	// JobResultMethod returns job result once the job is done
+	JobResultMethod = "job/result"
+	// JobCancelMethod cancels a pending or running job
+	JobCancelMethod = "job/cancel"
+	// JobCompletedNotification is sent to the job session when the job reaches a final state
+	JobCompletedNotification = "notifications/job/completed"
+)
+
+// JobState represents job state
+type JobState string
+
+const (
+	JobPending   JobState = "pending"
+	JobRunning   JobState = "running"
+	JobCompleted JobState = "completed"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// Done returns true for final states
+func (s JobState) Done() bool {
+	return s == JobCompleted || s == JobFailed || s == JobCancelled
+}
+
+// Job represents long-running request executed asynchronously
+type Job struct {
+	ID        string          `json:"jobId"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	State     JobState        `json:"state"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *jsonrpc.Error  `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// JobHandle is returned in place of the result of a request that runs as a job
+type JobHandle struct {
+	ID    string   `json:"jobId"`
+	State JobState `json:"state"`
+}
+
+// JobWatcher is implemented by clients that deliver job completion notifications; the returned channel
+// receives the job once completed, the returned function stops watching.
+type JobWatcher interface {
+	WatchJob(id string) (<-chan *Job, func())
+}
+
+// AwaitJob waits for the job to complete: it returns as soon as a completion notification arrives
+// (when the client implements JobWatcher) and otherwise polls job/status every pollInterval.
+// The job result is fetched with job/result when the notification or status carries none.
+func AwaitJob(ctx context.Context, client Transport, id string, pollInterval time.Duration) (*Job, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	var completed <-chan *Job
+	if watcher, ok := client.(JobWatcher); ok {
+		var stop func()
+		completed, stop = watcher.WatchJob(id)
+		defer stop()
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := callJob(ctx, client, JobStatusMethod, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.State.Done() {
+			return callJob(ctx, client, JobResultMethod, id)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case job = <-completed:
+			if job.State == JobCompleted && job.Result == nil {
+				return callJob(ctx, client, JobResultMethod, id)
+			}
+			return job, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// callJob calls job method and decodes job from its result
+func callJob(ctx context.Context, client Transport, method, id string) (*Job, error) {
+	params, err := json.Marshal(map[string]string{"jobId": id})
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Send(ctx, &jsonrpc.Request{Jsonrpc: jsonrpc.Version, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	job := &Job{}
+	if err = json.Unmarshal(response.Result, job); err != nil {
+		return nil, fmt.Errorf("failed to decode %v result: %w", method, err)
+	}
+	return job, nil
+}
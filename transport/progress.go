@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/viant/jsonrpc"
+)
+
+// ProgressNotification is the notification method used to report request progress
+const ProgressNotification = "notifications/progress"
+
+// ProgressParams represents progress notification parameters
+type ProgressParams struct {
+	ProgressToken any     `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// ProgressReporter emits progress of the current request; total is omitted when zero
+type ProgressReporter func(ctx context.Context, done, total float64, message string) error
+
+// ProgressHandler receives progress of an outgoing request
+type ProgressHandler func(params *ProgressParams)
+
+type progressKey string
+
+const (
+	progressReporterKey = progressKey("jsonrpc-progress-reporter")
+	progressHandlerKey  = progressKey("jsonrpc-progress-handler")
+)
+
+// ProgressToken returns progress token from request params: params._meta.progressToken or params.progressToken
+func ProgressToken(request *jsonrpc.Request) (any, bool) {
+	if request == nil || len(request.Params) == 0 {
+		return nil, false
+	}
+	params := struct {
+		Meta *struct {
+			ProgressToken any `json:"progressToken"`
+		} `json:"_meta"`
+		ProgressToken any `json:"progressToken"`
+	}{}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return nil, false
+	}
+	if params.Meta != nil && params.Meta.ProgressToken != nil {
+		return params.Meta.ProgressToken, true
+	}
+	return params.ProgressToken, params.ProgressToken != nil
+}
+
+// SetProgressToken sets params._meta.progressToken on request with object (or empty) params
+func SetProgressToken(request *jsonrpc.Request, token any) error {
+	return setMeta(request, "progressToken", token)
+}
+
+// WithProgressReporter returns context with progress reporter of the current request
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey, reporter)
+}
+
+// Progress reports progress of the request being served; it is a no-op when the client did not ask for progress
+func Progress(ctx context.Context, done, total float64, message string) error {
+	if ctx == nil {
+		return nil
+	}
+	reporter, ok := ctx.Value(progressReporterKey).(ProgressReporter)
+	if !ok {
+		return nil
+	}
+	return reporter(ctx, done, total, message)
+}
+
+// WithProgressHandler returns context requesting progress for a single client call
+func WithProgressHandler(ctx context.Context, handler ProgressHandler) context.Context {
+	return context.WithValue(ctx, progressHandlerKey, handler)
+}
+
+// ProgressHandlerFromContext returns per-call progress handler
+func ProgressHandlerFromContext(ctx context.Context) (ProgressHandler, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	handler, ok := ctx.Value(progressHandlerKey).(ProgressHandler)
+	return handler, ok && handler != nil
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/viant/jsonrpc"
+)
+
+// JSON-RPC error codes returned by MethodAuthorizer
+const (
+	// ErrorCodeUnauthenticated is returned when a protected method is called without a grant or principal
+	ErrorCodeUnauthenticated = -32001
+	// ErrorCodeForbidden is returned when the caller lacks required scopes or a policy denies the call
+	ErrorCodeForbidden = -32003
+)
+
+var (
+	// ErrUnauthenticated indicates the call carries no grant or principal
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden indicates the caller is not allowed to call the method
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Identity represents the caller of a method: bearer principal or BFF grant
+type Identity struct {
+	Subject   string
+	Scopes    []string
+	Principal *Principal
+	Grant     *Grant
+}
+
+// HasScope returns true if identity carries scope
+func (i *Identity) HasScope(scope string) bool {
+	if i == nil {
+		return false
+	}
+	for _, candidate := range i.Scopes {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IdentityFromContext returns caller identity; bearer principal takes precedence over BFF grant
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		ret := &Identity{Subject: principal.Subject, Scopes: principal.Scopes, Principal: principal}
+		if grant, ok := GrantFromContext(ctx); ok {
+			ret.Grant = grant
+		}
+		return ret, true
+	}
+	if grant, ok := GrantFromContext(ctx); ok {
+		return &Identity{Subject: grant.Subject, Scopes: grant.Scopes, Grant: grant}, true
+	}
+	return nil, false
+}
+
+// Policy decides whether identity (nil when unauthenticated) may call method; a non nil error denies the call.
+// Returning ErrUnauthenticated (or wrapping it) reports ErrorCodeUnauthenticated, any other error ErrorCodeForbidden.
+type Policy func(ctx context.Context, method string, identity *Identity) error
+
+// MethodRule maps a method pattern to required scopes and policies
+type MethodRule struct {
+	// Pattern is an exact method name, a prefix ending with "*" (e.g. "tools/*") or "*" for all methods
+	Pattern string
+	// Scopes lists scopes that are all required
+	Scopes []string
+	// Public allows the method without authentication; policies still run
+	Public   bool
+	Policies []Policy
+}
+
+func (r *MethodRule) matches(method string) bool {
+	if prefix, ok := strings.CutSuffix(r.Pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return r.Pattern == method
+}
+
+// specificity ranks exact names above prefixes, and longer prefixes above shorter ones
+func (r *MethodRule) specificity() int {
+	if strings.HasSuffix(r.Pattern, "*") {
+		return len(r.Pattern) - 1
+	}
+	return len(r.Pattern) + 1<<16
+}
+
+// MethodAuthorizer enforces per-method scopes and policies; it implements base.Authorizer
+type MethodAuthorizer struct {
+	rules    []*MethodRule
+	policies []Policy
+	// denyUnmatched rejects methods without a matching rule
+	denyUnmatched bool
+}
+
+// AuthorizerOption represents method authorizer option
+type AuthorizerOption func(a *MethodAuthorizer)
+
+// WithMethodScopes requires all scopes for methods matching pattern
+func WithMethodScopes(pattern string, scopes ...string) AuthorizerOption {
+	return func(a *MethodAuthorizer) { a.rule(pattern).Scopes = append(a.rule(pattern).Scopes, scopes...) }
+}
+
+// WithPublicMethods allows methods matching patterns without authentication (e.g. "initialize", "ping")
+func WithPublicMethods(patterns ...string) AuthorizerOption {
+	return func(a *MethodAuthorizer) {
+		for _, pattern := range patterns {
+			a.rule(pattern).Public = true
+		}
+	}
+}
+
+// WithMethodPolicy adds policy for methods matching pattern
+func WithMethodPolicy(pattern string, policy Policy) AuthorizerOption {
+	return func(a *MethodAuthorizer) { a.rule(pattern).Policies = append(a.rule(pattern).Policies, policy) }
+}
+
+// WithPolicy adds policy evaluated for every method after the method rule
+func WithPolicy(policy Policy) AuthorizerOption {
+	return func(a *MethodAuthorizer) { a.policies = append(a.policies, policy) }
+}
+
+// WithDenyUnmatched rejects methods that match no rule (default: unmatched methods only require authentication)
+func WithDenyUnmatched() AuthorizerOption {
+	return func(a *MethodAuthorizer) { a.denyUnmatched = true }
+}
+
+func (a *MethodAuthorizer) rule(pattern string) *MethodRule {
+	for _, candidate := range a.rules {
+		if candidate.Pattern == pattern {
+			return candidate
+		}
+	}
+	ret := &MethodRule{Pattern: pattern}
+	a.rules = append(a.rules, ret)
+	return ret
+}
+
+// Match returns the most specific rule matching method
+func (a *MethodAuthorizer) Match(method string) (*MethodRule, bool) {
+	var ret *MethodRule
+	for _, candidate := range a.rules {
+		if candidate.matches(method) && (ret == nil || candidate.specificity() > ret.specificity()) {
+			ret = candidate
+		}
+	}
+	return ret, ret != nil
+}
+
+// Authorize checks method against the matching rule and policies
+func (a *MethodAuthorizer) Authorize(ctx context.Context, method string) *jsonrpc.Error {
+	identity, _ := IdentityFromContext(ctx)
+	rule, ok := a.Match(method)
+	if !ok && a.denyUnmatched {
+		return newAuthorizationError(method, ErrForbidden)
+	}
+	if identity == nil && (rule == nil || !rule.Public) {
+		return newAuthorizationError(method, ErrUnauthenticated)
+	}
+	if rule != nil {
+		for _, scope := range rule.Scopes {
+			if !identity.HasScope(scope) {
+				return newAuthorizationError(method, fmt.Errorf("%w: missing scope %v", ErrForbidden, scope))
+			}
+		}
+		if err := evaluate(ctx, method, identity, rule.Policies); err != nil {
+			return err
+		}
+	}
+	return evaluate(ctx, method, identity, a.policies)
+}
+
+func evaluate(ctx context.Context, method string, identity *Identity, policies []Policy) *jsonrpc.Error {
+	for _, policy := range policies {
+		if err := policy(ctx, method, identity); err != nil {
+			return newAuthorizationError(method, err)
+		}
+	}
+	return nil
+}
+
+func newAuthorizationError(method string, err error) *jsonrpc.Error {
+	code := ErrorCodeForbidden
+	if errors.Is(err, ErrUnauthenticated) {
+		code = ErrorCodeUnauthenticated
+	}
+	return jsonrpc.NewError(code, err.Error(), map[string]string{"method": method})
+}
+
+// NewMethodAuthorizer creates method authorizer
+func NewMethodAuthorizer(options ...AuthorizerOption) *MethodAuthorizer {
+	ret := &MethodAuthorizer{}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
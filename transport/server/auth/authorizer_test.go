@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+)
+
+func TestMethodAuthorizer_Authorize(t *testing.T) {
+	denyGuest := func(ctx context.Context, method string, identity *Identity) error {
+		if identity != nil && identity.Subject == "guest" {
+			return fmt.Errorf("%w: subject %v", ErrForbidden, identity.Subject)
+		}
+		return nil
+	}
+	authorizer := NewMethodAuthorizer(
+		WithPublicMethods("initialize", "ping"),
+		WithMethodScopes("tools/*", "tools:read"),
+		WithMethodScopes("tools/call", "tools:read", "tools:call"),
+		WithMethodPolicy("admin/*", denyGuest),
+	)
+	strict := NewMethodAuthorizer(WithPublicMethods("initialize"), WithDenyUnmatched())
+	withPrincipal := func(subject string, scopes ...string) context.Context {
+		return WithPrincipal(context.Background(), &Principal{Subject: subject, Scopes: scopes})
+	}
+	withGrant := func(subject string, scopes ...string) context.Context {
+		return jsonrpc.WithRequestInfo(context.Background(), &jsonrpc.RequestInfo{Grant: &Grant{Subject: subject, Scopes: scopes}})
+	}
+
+	var testCases = []struct {
+		description string
+		authorizer  *MethodAuthorizer
+		ctx         context.Context
+		method      string
+		expectCode  int
+	}{
+		{description: "public method without identity", authorizer: authorizer, ctx: context.Background(), method: "initialize"},
+		{description: "protected method without identity", authorizer: authorizer, ctx: context.Background(), method: "tools/list", expectCode: ErrorCodeUnauthenticated},
+		{description: "prefix scope satisfied", authorizer: authorizer, ctx: withPrincipal("alice", "tools:read"), method: "tools/list"},
+		{description: "exact rule overrides prefix", authorizer: authorizer, ctx: withPrincipal("alice", "tools:read"), method: "tools/call", expectCode: ErrorCodeForbidden},
+		{description: "all scopes of exact rule", authorizer: authorizer, ctx: withPrincipal("alice", "tools:read", "tools:call"), method: "tools/call"},
+		{description: "bff grant scopes", authorizer: authorizer, ctx: withGrant("bob", "tools:read"), method: "tools/list"},
+		{description: "bff grant missing scope", authorizer: authorizer, ctx: withGrant("bob"), method: "tools/list", expectCode: ErrorCodeForbidden},
+		{description: "unmatched method requires identity only", authorizer: authorizer, ctx: withGrant("bob"), method: "resources/list"},
+		{description: "subject policy denies", authorizer: authorizer, ctx: withPrincipal("guest"), method: "admin/reset", expectCode: ErrorCodeForbidden},
+		{description: "subject policy allows", authorizer: authorizer, ctx: withPrincipal("alice"), method: "admin/reset"},
+		{description: "deny unmatched", authorizer: strict, ctx: withPrincipal("alice"), method: "tools/list", expectCode: ErrorCodeForbidden},
+	}
+	for _, testCase := range testCases {
+		err := testCase.authorizer.Authorize(testCase.ctx, testCase.method)
+		if testCase.expectCode == 0 {
+			assert.Nil(t, err, testCase.description)
+			continue
+		}
+		if assert.NotNil(t, err, testCase.description) {
+			assert.Equal(t, testCase.expectCode, err.Code, testCase.description)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package authtest provides conformance tests for auth.Store implementations.
+package authtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+// NewStore creates an empty store keeping rotated ids valid for rotateGrace
+type NewStore func(t *testing.T, rotateGrace time.Duration) auth.Store
+
+// Option represents conformance option
+type Option func(c *conformance)
+
+type conformance struct {
+	statelessTouch bool
+}
+
+// WithStatelessTouch is for stores that cannot update grants in place (e.g. auth.CookieStore): Touch must accept
+// valid ids and reject unknown ones, but is not required to change LastUsedAt.
+func WithStatelessTouch() Option {
+	return func(c *conformance) { c.statelessTouch = true }
+}
+
+// RunStoreConformance verifies store against the auth.Store contract, including rotation grace,
+// reuse detection and family revocation; stores implementing auth.SecurityNotifier must emit auth.GrantReused.
+func RunStoreConformance(t *testing.T, newStore NewStore, options ...Option) {
+	config := &conformance{}
+	for _, option := range options {
+		option(config)
+	}
+	ctx := context.Background()
+	put := func(t *testing.T, store auth.Store, subject string) *auth.Grant {
+		grant := auth.NewGrant(subject)
+		grant.Scopes = []string{"tools:read"}
+		if !assert.Nil(t, store.Put(ctx, grant)) {
+			t.FailNow()
+		}
+		return grant
+	}
+
+	t.Run("put and get", func(t *testing.T) {
+		store := newStore(t, time.Minute)
+		grant := put(t, store, "alice")
+		actual, err := store.Get(ctx, grant.ID)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, grant.Subject, actual.Subject)
+		assert.Equal(t, grant.FamilyID, actual.FamilyID)
+		assert.Equal(t, grant.Scopes, actual.Scopes)
+		actual.Scopes[0] = "mutated"
+		again, _ := store.Get(ctx, grant.ID)
+		assert.Equal(t, "tools:read", again.Scopes[0], "returned grant must be a copy")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		store := newStore(t, time.Minute)
+		_, err := store.Get(ctx, "missing")
+		assert.ErrorIs(t, err, auth.ErrNotFound)
+		_, err = store.Rotate(ctx, "missing", &auth.Grant{Subject: "alice"})
+		assert.ErrorIs(t, err, auth.ErrNotFound)
+	})
+
+	t.Run("touch", func(t *testing.T) {
+		store := newStore(t, time.Minute)
+		grant := put(t, store, "alice")
+		at := time.Now().Add(time.Second)
+		assert.Nil(t, store.Touch(ctx, grant.ID, at))
+		assert.ErrorIs(t, store.Touch(ctx, "missing", at), auth.ErrNotFound)
+
+		revoked := put(t, store, "bob")
+		assert.Nil(t, store.Revoke(ctx, revoked.ID))
+		assert.ErrorIs(t, store.Touch(ctx, revoked.ID, at), auth.ErrNotFound, "revoked grant")
+		expired := auth.NewGrant("carol")
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		if assert.Nil(t, store.Put(ctx, expired)) {
+			assert.ErrorIs(t, store.Touch(ctx, expired.ID, at), auth.ErrNotFound, "expired grant")
+		}
+		if config.statelessTouch {
+			return
+		}
+		actual, err := store.Get(ctx, grant.ID)
+		if assert.Nil(t, err) {
+			assert.WithinDuration(t, at, actual.LastUsedAt, time.Millisecond)
+		}
+	})
+
+	t.Run("rotate within grace", func(t *testing.T) {
+		store := newStore(t, time.Hour)
+		grant := put(t, store, "alice")
+		newID, err := store.Rotate(ctx, grant.ID, &auth.Grant{Subject: "alice", Scopes: grant.Scopes})
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.NotEqual(t, grant.ID, newID)
+		rotated, err := store.Get(ctx, newID)
+		if assert.Nil(t, err) {
+			assert.Equal(t, grant.FamilyID, rotated.FamilyID, "rotation keeps the family")
+		}
+		_, err = store.Get(ctx, grant.ID)
+		assert.Nil(t, err, "rotated id is valid within the grace window")
+	})
+
+	t.Run("reuse after grace revokes family", func(t *testing.T) {
+		store := newStore(t, 0)
+		var events []*auth.SecurityEvent
+		var mux sync.Mutex
+		notifier, notifies := store.(auth.SecurityNotifier)
+		if notifies {
+			notifier.SetSecurityHook(func(ctx context.Context, event *auth.SecurityEvent) {
+				mux.Lock()
+				events = append(events, event)
+				mux.Unlock()
+			})
+		}
+		grant := put(t, store, "alice")
+		sibling := &auth.Grant{ID: auth.NewGrant("alice").ID, FamilyID: grant.FamilyID, Subject: "alice"}
+		assert.Nil(t, store.Put(ctx, sibling))
+		newID, err := store.Rotate(ctx, grant.ID, &auth.Grant{Subject: "alice"})
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		_, err = store.Get(ctx, grant.ID)
+		assert.ErrorIs(t, err, auth.ErrGrantReused, "replayed rotated id")
+		_, err = store.Get(ctx, newID)
+		assert.ErrorIs(t, err, auth.ErrNotFound, "family revoked: rotated grant")
+		_, err = store.Get(ctx, sibling.ID)
+		assert.ErrorIs(t, err, auth.ErrNotFound, "family revoked: sibling grant")
+		if notifies {
+			mux.Lock()
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, auth.GrantReused, events[0].Type)
+				assert.Equal(t, auth.GrantIDHash(grant.ID), events[0].GrantIDHash)
+				assert.Equal(t, grant.FamilyID, events[0].FamilyID)
+			}
+			mux.Unlock()
+		}
+	})
+
+	t.Run("rotate reused id", func(t *testing.T) {
+		store := newStore(t, 0)
+		grant := put(t, store, "alice")
+		newID, err := store.Rotate(ctx, grant.ID, &auth.Grant{Subject: "alice"})
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, err = store.Rotate(ctx, grant.ID, &auth.Grant{Subject: "alice"})
+		assert.ErrorIs(t, err, auth.ErrGrantReused)
+		_, err = store.Get(ctx, newID)
+		assert.ErrorIs(t, err, auth.ErrNotFound)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		store := newStore(t, time.Minute)
+		grant := put(t, store, "alice")
+		other := put(t, store, "bob")
+		assert.Nil(t, store.Revoke(ctx, grant.ID))
+		_, err := store.Get(ctx, grant.ID)
+		assert.ErrorIs(t, err, auth.ErrNotFound)
+		_, err = store.Get(ctx, other.ID)
+		assert.Nil(t, err, "other grants are kept")
+	})
+
+	t.Run("revoke family", func(t *testing.T) {
+		store := newStore(t, time.Minute)
+		grant := put(t, store, "alice")
+		newID, err := store.Rotate(ctx, grant.ID, &auth.Grant{Subject: "alice"})
+		if !assert.Nil(t, err) {
+			return
+		}
+		other := put(t, store, "bob")
+		assert.Nil(t, store.RevokeFamily(ctx, grant.FamilyID))
+		for _, id := range []string{grant.ID, newID} {
+			_, err = store.Get(ctx, id)
+			assert.Error(t, err, id)
+		}
+		_, err = store.Get(ctx, other.ID)
+		assert.Nil(t, err, "other families are kept")
+	})
+}
@@ -0,0 +1,24 @@
+package authtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	RunStoreConformance(t, func(t *testing.T, rotateGrace time.Duration) auth.Store {
+		return auth.NewMemoryStore(time.Hour, 24*time.Hour, rotateGrace)
+	})
+}
+
+func TestCookieStore_Conformance(t *testing.T) {
+	RunStoreConformance(t, func(t *testing.T, rotateGrace time.Duration) auth.Store {
+		keyring, err := auth.NewCookieKeyring(&auth.CookieKey{ID: "k1", Secret: make([]byte, 32)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return auth.NewCookieStore(keyring, time.Hour, 24*time.Hour, rotateGrace)
+	}, WithStatelessTouch())
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// BearerAuthenticator authenticates HTTP requests carrying "Authorization: Bearer <token>"
+// and writes RFC 6750 WWW-Authenticate challenges for rejected requests.
+type BearerAuthenticator struct {
+	verifier TokenVerifier
+	realm    string
+	audience []string
+	metadata *ProtectedResourceMetadata
+}
+
+// BearerOption represents bearer authenticator option
+type BearerOption func(a *BearerAuthenticator)
+
+// WithRealm sets challenge realm
+func WithRealm(realm string) BearerOption {
+	return func(a *BearerAuthenticator) { a.realm = realm }
+}
+
+// WithAudience requires the token audience to contain one of the values
+func WithAudience(audience ...string) BearerOption {
+	return func(a *BearerAuthenticator) { a.audience = audience }
+}
+
+// WithResourceMetadata advertises RFC 9728 metadata in challenges (resource_metadata parameter);
+// unless WithAudience is used, tokens must carry the metadata resource as audience.
+func WithResourceMetadata(metadata *ProtectedResourceMetadata) BearerOption {
+	return func(a *BearerAuthenticator) { a.metadata = metadata }
+}
+
+// Metadata returns protected resource metadata, or nil
+func (a *BearerAuthenticator) Metadata() *ProtectedResourceMetadata {
+	return a.metadata
+}
+
+// With returns a copy of the authenticator with options applied; the receiver is left unchanged
+func (a *BearerAuthenticator) With(options ...BearerOption) *BearerAuthenticator {
+	ret := *a
+	for _, option := range options {
+		option(&ret)
+	}
+	return &ret
+}
+
+func (a *BearerAuthenticator) metadataURL() string {
+	if a.metadata == nil {
+		return ""
+	}
+	return a.metadata.URL()
+}
+
+// Authenticate verifies request bearer token and returns its principal
+func (a *BearerAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok {
+		return nil, ErrMissingToken
+	}
+	principal, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		return nil, ErrInvalidToken
+	}
+	if audience := a.expectedAudience(); len(audience) > 0 && !hasAny(principal.Audience, audience) {
+		return nil, fmt.Errorf("%w: audience %v does not match %v", ErrInvalidToken, principal.Audience, audience)
+	}
+	return principal, nil
+}
+
+func (a *BearerAuthenticator) expectedAudience() []string {
+	if len(a.audience) > 0 {
+		return a.audience
+	}
+	if a.metadata != nil && a.metadata.Resource != "" {
+		return []string{a.metadata.Resource}
+	}
+	return nil
+}
+
+func hasAny(values, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Challenge writes 401 response with WWW-Authenticate header describing err
+func (a *BearerAuthenticator) Challenge(w http.ResponseWriter, r *http.Request, err error) {
+	params := []string{fmt.Sprintf("realm=%q", a.realm)}
+	if metadataURL := a.metadataURL(); metadataURL != "" {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", metadataURL))
+	}
+	if err != nil && !errors.Is(err, ErrMissingToken) {
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", err.Error()))
+	}
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(params, ", "))
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// BearerToken returns bearer token from Authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// NewBearerAuthenticator creates bearer authenticator using verifier
+func NewBearerAuthenticator(verifier TokenVerifier, options ...BearerOption) *BearerAuthenticator {
+	ret := &BearerAuthenticator{verifier: verifier, realm: "mcp"}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
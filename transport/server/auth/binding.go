@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrBindingMismatch indicates the request device does not match grant binding hints
+var ErrBindingMismatch = errors.New("grant device binding mismatch")
+
+// BindingMode controls device binding enforcement
+type BindingMode string
+
+const (
+	// BindingOff records hints on rotated grants without checking them
+	BindingOff BindingMode = "off"
+	// BindingWarn reports mismatches but keeps the grant
+	BindingWarn BindingMode = "warn"
+	// BindingStrict reports mismatches and revokes the grant family
+	BindingStrict BindingMode = "strict"
+)
+
+// BindingEvent is the audit record of a device binding mismatch
+type BindingEvent struct {
+	Time time.Time   `json:"time"`
+	Mode BindingMode `json:"mode"`
+	// GrantIDHash is GrantIDHash of the grant id; the id itself is a credential and is never recorded
+	GrantIDHash string `json:"grantIdHash"`
+	FamilyID    string `json:"familyId"`
+	Subject     string `json:"subject"`
+	// UAMismatch and IPMismatch report which hint did not match
+	UAMismatch bool   `json:"uaMismatch,omitempty"`
+	IPMismatch bool   `json:"ipMismatch,omitempty"`
+	ExpectedIP string `json:"expectedIp,omitempty"`
+	ClientIP   string `json:"clientIp,omitempty"`
+	// Revoked is true when the grant family was revoked (strict mode)
+	Revoked bool `json:"revoked"`
+}
+
+// DeviceBinding computes and checks grant device hints (User-Agent hash and client IP prefix)
+type DeviceBinding struct {
+	Mode BindingMode
+	// IPv4PrefixBits and IPv6PrefixBits set IP tolerance (default: /24 and /64); negative disables IP binding
+	IPv4PrefixBits int
+	IPv6PrefixBits int
+	// TrustProxy takes the client IP from X-Forwarded-For: the right-most entry that is not a trusted proxy.
+	// Entries left of it are client controlled and ignored.
+	TrustProxy bool
+	// TrustedProxies lists proxy CIDRs or IPs (e.g. "10.0.0.0/8"); when empty only the direct peer is trusted,
+	// so the right-most X-Forwarded-For entry is used
+	TrustedProxies []string
+	// OnMismatch receives an audit event for every mismatch
+	OnMismatch func(event *BindingEvent)
+}
+
+// UAHash returns hex sha256 of the request User-Agent, or "" when absent
+func UAHash(r *http.Request) string {
+	userAgent := r.Header.Get("User-Agent")
+	if userAgent == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(userAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// ClientIP returns request client IP, or nil when it cannot be parsed
+func (b *DeviceBinding) ClientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if !b.TrustProxy || ip == nil || !b.trustedProxy(ip, true) {
+		return ip
+	}
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			return ip
+		}
+		ip = hop
+		if !b.trustedProxy(hop, false) {
+			return hop
+		}
+	}
+	return ip
+}
+
+// trustedProxy returns true if ip is a trusted proxy; without TrustedProxies only the direct peer is trusted
+func (b *DeviceBinding) trustedProxy(ip net.IP, peer bool) bool {
+	if len(b.TrustedProxies) == 0 {
+		return peer
+	}
+	for _, proxy := range b.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPHint returns client IP prefix in CIDR notation (e.g. "203.0.113.0/24"), or "" when IP binding is disabled
+func (b *DeviceBinding) IPHint(r *http.Request) string {
+	prefix := b.prefix(b.ClientIP(r))
+	if prefix == nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+func (b *DeviceBinding) prefix(ip net.IP) *net.IPNet {
+	if ip == nil {
+		return nil
+	}
+	bits, size := b.IPv6PrefixBits, net.IPv6len*8
+	if bits == 0 {
+		bits = 64
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip, size, bits = v4, net.IPv4len*8, b.IPv4PrefixBits
+		if bits == 0 {
+			bits = 24
+		}
+	}
+	if bits < 0 {
+		return nil
+	}
+	if bits > size {
+		bits = size
+	}
+	mask := net.CIDRMask(bits, size)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// Bind sets grant hints from the request, keeping hints already present
+func (b *DeviceBinding) Bind(r *http.Request, grant *Grant) {
+	if grant.UAHash == "" {
+		grant.UAHash = UAHash(r)
+	}
+	if grant.IPHint == "" {
+		grant.IPHint = b.IPHint(r)
+	}
+}
+
+// Check compares request with grant hints; empty hints are not checked. It returns the audit event of a mismatch, or nil.
+func (b *DeviceBinding) Check(r *http.Request, grant *Grant) *BindingEvent {
+	if b == nil || b.Mode == "" || b.Mode == BindingOff {
+		return nil
+	}
+	event := &BindingEvent{Time: time.Now(), Mode: b.Mode, GrantIDHash: GrantIDHash(grant.ID), FamilyID: grant.FamilyID, Subject: grant.Subject}
+	if grant.UAHash != "" && grant.UAHash != UAHash(r) {
+		event.UAMismatch = true
+	}
+	if grant.IPHint != "" {
+		if ip := b.ClientIP(r); !b.ipMatches(grant.IPHint, ip) {
+			event.IPMismatch = true
+			event.ExpectedIP = grant.IPHint
+			if ip != nil {
+				event.ClientIP = ip.String()
+			}
+		}
+	}
+	if !event.UAMismatch && !event.IPMismatch {
+		return nil
+	}
+	event.Revoked = b.Mode == BindingStrict
+	if b.OnMismatch != nil {
+		b.OnMismatch(event)
+	}
+	return event
+}
+
+// ipMatches checks ip against a CIDR hint, or against the configured prefix of a plain IP hint
+func (b *DeviceBinding) ipMatches(hint string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(hint); err == nil {
+		return network.Contains(ip)
+	}
+	expected := b.prefix(net.ParseIP(hint))
+	return expected == nil || expected.Contains(ip)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceBinding_Check(t *testing.T) {
+	newRequest := func(remoteAddr, userAgent string) *http.Request {
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", userAgent)
+		return r
+	}
+	bound := newRequest("203.0.113.10:5000", "browser/1.0")
+	var testCases = []struct {
+		description    string
+		binding        *DeviceBinding
+		grant          *Grant
+		request        *http.Request
+		expectUA       bool
+		expectIP       bool
+		expectRevoke   bool
+		expectClientIP string
+	}{
+		{description: "same device", binding: &DeviceBinding{Mode: BindingStrict}, request: newRequest("203.0.113.10:6000", "browser/1.0")},
+		{description: "ip within /24 tolerance", binding: &DeviceBinding{Mode: BindingStrict}, request: newRequest("203.0.113.99:6000", "browser/1.0")},
+		{description: "ip outside prefix", binding: &DeviceBinding{Mode: BindingStrict}, request: newRequest("198.51.100.1:6000", "browser/1.0"), expectIP: true, expectRevoke: true, expectClientIP: "198.51.100.1"},
+		{description: "wider tolerance", binding: &DeviceBinding{Mode: BindingStrict, IPv4PrefixBits: 8}, request: newRequest("203.1.2.3:6000", "browser/1.0"), grant: &Grant{IPHint: "203.0.113.10"}},
+		{description: "ip binding disabled", binding: &DeviceBinding{Mode: BindingStrict, IPv4PrefixBits: -1}, request: newRequest("198.51.100.1:6000", "browser/1.0"), grant: &Grant{UAHash: UAHash(bound)}},
+		{description: "user agent changed", binding: &DeviceBinding{Mode: BindingWarn}, request: newRequest("203.0.113.10:6000", "curl/8"), expectUA: true},
+		{description: "off mode", binding: &DeviceBinding{Mode: BindingOff}, request: newRequest("198.51.100.1:6000", "curl/8")},
+		{description: "unbound grant", binding: &DeviceBinding{Mode: BindingStrict}, request: newRequest("198.51.100.1:6000", "curl/8"), grant: &Grant{}},
+		{description: "forwarded client ip", binding: &DeviceBinding{Mode: BindingStrict, TrustProxy: true, TrustedProxies: []string{"10.0.0.0/8"}}, request: func() *http.Request {
+			r := newRequest("10.0.0.1:6000", "browser/1.0")
+			r.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+			return r
+		}()},
+		{description: "forwarded client ip behind single proxy", binding: &DeviceBinding{Mode: BindingStrict, TrustProxy: true}, request: func() *http.Request {
+			r := newRequest("10.0.0.1:6000", "browser/1.0")
+			r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+			return r
+		}()},
+		{description: "spoofed left-most forwarded entry", binding: &DeviceBinding{Mode: BindingStrict, TrustProxy: true, TrustedProxies: []string{"10.0.0.0/8"}}, request: func() *http.Request {
+			r := newRequest("10.0.0.1:6000", "browser/1.0")
+			r.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.1")
+			return r
+		}(), expectIP: true, expectRevoke: true, expectClientIP: "198.51.100.1"},
+		{description: "forwarded header from untrusted peer", binding: &DeviceBinding{Mode: BindingStrict, TrustProxy: true, TrustedProxies: []string{"10.0.0.0/8"}}, request: func() *http.Request {
+			r := newRequest("198.51.100.1:6000", "browser/1.0")
+			r.Header.Set("X-Forwarded-For", "203.0.113.7")
+			return r
+		}(), expectIP: true, expectRevoke: true, expectClientIP: "198.51.100.1"},
+		{description: "unparsable client ip", binding: &DeviceBinding{Mode: BindingWarn}, request: newRequest("pipe", "browser/1.0"), expectIP: true},
+	}
+	for _, testCase := range testCases {
+		grant := testCase.grant
+		if grant == nil {
+			grant = &Grant{ID: "g1", FamilyID: "f1", Subject: "alice"}
+			(&DeviceBinding{}).Bind(bound, grant)
+		}
+		var audited []*BindingEvent
+		testCase.binding.OnMismatch = func(event *BindingEvent) { audited = append(audited, event) }
+		event := testCase.binding.Check(testCase.request, grant)
+		if !testCase.expectUA && !testCase.expectIP {
+			assert.Nil(t, event, testCase.description)
+			assert.Empty(t, audited, testCase.description)
+			continue
+		}
+		if !assert.NotNil(t, event, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expectUA, event.UAMismatch, testCase.description)
+		assert.Equal(t, testCase.expectIP, event.IPMismatch, testCase.description)
+		assert.Equal(t, testCase.expectRevoke, event.Revoked, testCase.description)
+		assert.Equal(t, testCase.expectClientIP, event.ClientIP, testCase.description)
+		assert.Equal(t, GrantIDHash(grant.ID), event.GrantIDHash, testCase.description)
+		assert.NotContains(t, fmt.Sprintf("%+v", *event), grant.ID, testCase.description)
+		assert.Len(t, audited, 1, testCase.description)
+	}
+}
+
+func TestDeviceBinding_Bind(t *testing.T) {
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	r.RemoteAddr = "[2001:db8::1]:443"
+	r.Header.Set("User-Agent", "browser/1.0")
+	grant := &Grant{}
+	(&DeviceBinding{}).Bind(r, grant)
+	assert.Len(t, grant.UAHash, 64)
+	assert.Equal(t, "2001:db8::/64", grant.IPHint)
+}
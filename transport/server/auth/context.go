@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/viant/jsonrpc"
+)
+
+// GrantFromContext returns the auth grant resolved by the transport for the current request.
+func GrantFromContext(ctx context.Context) (*Grant, bool) {
+	info, ok := jsonrpc.RequestInfoFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	g, ok := info.Grant.(*Grant)
+	return g, ok && g != nil
+}
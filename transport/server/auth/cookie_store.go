@@ -0,0 +1,427 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieKey represents a named AES-256 key sealing grants
+type CookieKey struct {
+	// ID is embedded in sealed values to select the decryption key; it must not contain "."
+	ID string
+	// Secret is the 32 byte AES-256 key
+	Secret []byte
+}
+
+// CookieKeyring supplies CookieStore keys: the current key seals grants, any held key opens them
+type CookieKeyring interface {
+	Current() (*CookieKey, error)
+	Key(id string) (*CookieKey, bool)
+}
+
+// StaticCookieKeyring is an in-memory keyring supporting rotation
+type StaticCookieKeyring struct {
+	keys []*CookieKey
+	mux  sync.RWMutex
+}
+
+// Current returns sealing key
+func (k *StaticCookieKeyring) Current() (*CookieKey, error) {
+	k.mux.RLock()
+	defer k.mux.RUnlock()
+	if len(k.keys) == 0 {
+		return nil, fmt.Errorf("cookie keyring is empty")
+	}
+	return k.keys[0], nil
+}
+
+// Key returns opening key by id
+func (k *StaticCookieKeyring) Key(id string) (*CookieKey, bool) {
+	k.mux.RLock()
+	defer k.mux.RUnlock()
+	for _, candidate := range k.keys {
+		if candidate.ID == id {
+			return candidate, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate makes key current; grants sealed with previous keys still open until those keys are retired
+func (k *StaticCookieKeyring) Rotate(key *CookieKey) error {
+	if err := validateCookieKey(key); err != nil {
+		return err
+	}
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	k.keys = append([]*CookieKey{key}, k.keys...)
+	return nil
+}
+
+// Retire removes key; grants sealed with it are no longer found
+func (k *StaticCookieKeyring) Retire(id string) {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	for i, candidate := range k.keys {
+		if candidate.ID == id {
+			k.keys = append(k.keys[:i:i], k.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func validateCookieKey(key *CookieKey) error {
+	if key == nil || key.ID == "" || strings.Contains(key.ID, ".") {
+		return fmt.Errorf("invalid cookie key id: must be non empty and must not contain '.'")
+	}
+	if len(key.Secret) != 32 {
+		return fmt.Errorf("cookie key %v: secret must be 32 bytes (AES-256)", key.ID)
+	}
+	return nil
+}
+
+// NewCookieKeyring creates keyring with current key followed by previous keys still accepted for opening
+func NewCookieKeyring(current *CookieKey, previous ...*CookieKey) (*StaticCookieKeyring, error) {
+	ret := &StaticCookieKeyring{}
+	for _, key := range append([]*CookieKey{current}, previous...) {
+		if err := validateCookieKey(key); err != nil {
+			return nil, err
+		}
+		ret.keys = append(ret.keys, key)
+	}
+	return ret, nil
+}
+
+// RevocationList holds revoked and rotated grant keys of a CookieStore until their grants expire;
+// replicas should share one implementation (e.g. a small Redis hash).
+type RevocationList interface {
+	// Add lists key as revoked from at until until (zero: kept forever); listing a key again keeps the earliest at
+	Add(ctx context.Context, key string, at, until time.Time) error
+	// Lookup returns time key is revoked from, or false when key is not listed
+	Lookup(ctx context.Context, key string) (time.Time, bool, error)
+}
+
+// revocationPurgeInterval bounds how often MemoryRevocationList entries are scanned for expiry
+const revocationPurgeInterval = time.Minute
+
+// MemoryRevocationList is an in-memory RevocationList
+type MemoryRevocationList struct {
+	mux       sync.Mutex
+	entries   map[string]*revocation
+	nextPurge time.Time
+}
+
+type revocation struct {
+	at    time.Time
+	until time.Time
+}
+
+// Add lists key as revoked from at until until
+func (l *MemoryRevocationList) Add(_ context.Context, key string, at, until time.Time) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.purge(time.Now())
+	entry, ok := l.entries[key]
+	if ok && !entry.until.IsZero() && time.Now().After(entry.until) {
+		ok = false // expired, not yet purged
+	}
+	if !ok {
+		l.entries[key] = &revocation{at: at, until: until}
+		return nil
+	}
+	if at.Before(entry.at) {
+		entry.at = at
+	}
+	if entry.until.IsZero() || until.IsZero() {
+		entry.until = time.Time{}
+	} else if until.After(entry.until) {
+		entry.until = until
+	}
+	return nil
+}
+
+// purge drops expired entries at most once per revocationPurgeInterval; caller must hold the mutex
+func (l *MemoryRevocationList) purge(now time.Time) {
+	if now.Before(l.nextPurge) {
+		return
+	}
+	l.nextPurge = now.Add(revocationPurgeInterval)
+	for candidate, entry := range l.entries {
+		if !entry.until.IsZero() && now.After(entry.until) {
+			delete(l.entries, candidate)
+		}
+	}
+}
+
+// Lookup returns time key is revoked from
+func (l *MemoryRevocationList) Lookup(_ context.Context, key string) (time.Time, bool, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	entry, ok := l.entries[key]
+	if !ok || (!entry.until.IsZero() && time.Now().After(entry.until)) {
+		return time.Time{}, false, nil
+	}
+	return entry.at, true, nil
+}
+
+// NewMemoryRevocationList creates in-memory revocation list
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{entries: map[string]*revocation{}}
+}
+
+// CookieStore is a stateless Store sealing grants into AES-GCM encrypted values used directly as the auth cookie;
+// only revocations (Revoke, RevokeFamily, rotated ids) are held server side, until the grants would expire.
+//
+// Put replaces the grant ID with the sealed value. Sealed values cannot be updated in place: Touch only validates
+// the grant, and the sliding idle expiry is extended when the grant is rotated (rehydration touches then rotates).
+// Rotation keeps the absolute expiry of the rotated grant.
+type CookieStore struct {
+	keyring     CookieKeyring
+	revocations RevocationList
+	idleTTL     time.Duration
+	maxTTL      time.Duration
+	rotateGrace time.Duration
+	hook        SecurityHook
+	mux         sync.RWMutex
+}
+
+// CookieStoreOption represents cookie store option
+type CookieStoreOption func(s *CookieStore)
+
+// WithRevocationList sets revocation list (default: in-memory, per replica)
+func WithRevocationList(list RevocationList) CookieStoreOption {
+	return func(s *CookieStore) { s.revocations = list }
+}
+
+// SetSecurityHook sets hook receiving security events (e.g. GrantReused)
+func (s *CookieStore) SetSecurityHook(hook SecurityHook) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.hook = hook
+}
+
+// Put seals g and replaces g.ID with the sealed value
+func (s *CookieStore) Put(_ context.Context, g *Grant) error {
+	now := time.Now()
+	if g.ID == "" {
+		g.ID = uuid4()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = now
+	}
+	if g.LastUsedAt.IsZero() {
+		g.LastUsedAt = now
+	}
+	if g.ExpiresAt.IsZero() && s.idleTTL > 0 {
+		g.ExpiresAt = now.Add(s.idleTTL)
+	}
+	if g.MaxExpiresAt.IsZero() && s.maxTTL > 0 {
+		g.MaxExpiresAt = now.Add(s.maxTTL)
+	}
+	sealed, err := s.seal(g)
+	if err != nil {
+		return err
+	}
+	g.ID = sealed
+	return nil
+}
+
+// Get opens sealed id; tampered values, values sealed with unknown keys, expired and revoked grants are not found
+func (s *CookieStore) Get(ctx context.Context, id string) (*Grant, error) {
+	g, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	g.ID = id
+	return g, nil
+}
+
+// get returns opened grant with its internal id
+func (s *CookieStore) get(ctx context.Context, id string) (*Grant, error) {
+	g, err := s.open(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{familyKey(g.FamilyID), grantKey(g.ID)} {
+		if _, revoked, err := s.revocations.Lookup(ctx, key); err != nil {
+			return nil, err
+		} else if revoked {
+			return nil, ErrNotFound
+		}
+	}
+	now := time.Now()
+	at, rotated, err := s.revocations.Lookup(ctx, rotatedKey(g.ID))
+	if err != nil {
+		return nil, err
+	}
+	if rotated && !now.Before(at) {
+		return nil, s.reused(ctx, id, g)
+	}
+	if (!g.ExpiresAt.IsZero() && now.After(g.ExpiresAt)) || (!g.MaxExpiresAt.IsZero() && now.After(g.MaxExpiresAt)) {
+		return nil, ErrNotFound
+	}
+	return g, nil
+}
+
+// reused revokes the family of a rotated id presented after its grace window and emits GrantReused
+func (s *CookieStore) reused(ctx context.Context, id string, g *Grant) error {
+	if err := s.RevokeFamily(ctx, g.FamilyID); err != nil {
+		return err
+	}
+	s.mux.RLock()
+	hook := s.hook
+	s.mux.RUnlock()
+	if hook != nil {
+		hook(ctx, &SecurityEvent{Type: GrantReused, Time: time.Now(), GrantIDHash: GrantIDHash(id), FamilyID: g.FamilyID, Subject: g.Subject})
+	}
+	return ErrGrantReused
+}
+
+// Touch validates id like Get (revoked and expired grants return ErrNotFound); the idle expiry is extended
+// on Rotate since sealed values cannot be updated in place
+func (s *CookieStore) Touch(ctx context.Context, id string, _ time.Time) error {
+	_, err := s.get(ctx, id)
+	return err
+}
+
+// Rotate seals newGrant in the family of oldID with a sliding idle expiry; oldID stays valid for the grace window
+func (s *CookieStore) Rotate(ctx context.Context, oldID string, newGrant *Grant) (string, error) {
+	old, err := s.get(ctx, oldID)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	ng := *newGrant
+	ng.ID = uuid4()
+	ng.FamilyID = old.FamilyID
+	if ng.CreatedAt.IsZero() {
+		ng.CreatedAt = now
+	}
+	if ng.LastUsedAt.IsZero() {
+		ng.LastUsedAt = now
+	}
+	if ng.MaxExpiresAt.IsZero() {
+		ng.MaxExpiresAt = old.MaxExpiresAt
+	}
+	if ng.ExpiresAt.IsZero() && s.idleTTL > 0 {
+		ng.ExpiresAt = now.Add(s.idleTTL)
+		if !ng.MaxExpiresAt.IsZero() && ng.ExpiresAt.After(ng.MaxExpiresAt) {
+			ng.ExpiresAt = ng.MaxExpiresAt
+		}
+	}
+	sealed, err := s.seal(&ng)
+	if err != nil {
+		return "", err
+	}
+	// old id stays valid for the grace window; presenting it afterwards is reuse
+	if err = s.revocations.Add(ctx, rotatedKey(old.ID), now.Add(s.rotateGrace), old.MaxExpiresAt); err != nil {
+		return "", err
+	}
+	return sealed, nil
+}
+
+// Revoke lists the grant as revoked until it expires
+func (s *CookieStore) Revoke(ctx context.Context, id string) error {
+	g, err := s.open(id)
+	if err != nil {
+		return err
+	}
+	return s.revocations.Add(ctx, grantKey(g.ID), time.Now(), g.MaxExpiresAt)
+}
+
+// RevokeFamily lists the family as revoked; with a max TTL the entry is kept until any family grant could expire
+func (s *CookieStore) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	var until time.Time
+	if s.maxTTL > 0 {
+		until = now.Add(s.maxTTL)
+	}
+	return s.revocations.Add(ctx, familyKey(familyID), now, until)
+}
+
+// seal encrypts grant as "<keyID>.<base64url(nonce|ciphertext)>" authenticating the key id
+func (s *CookieStore) seal(g *Grant) (string, error) {
+	key, err := s.keyring.Current()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return key.ID + "." + base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, data, []byte(key.ID))), nil
+}
+
+// open decrypts sealed value; any failure is reported as ErrNotFound
+func (s *CookieStore) open(sealed string) (*Grant, error) {
+	keyID, encoded, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return nil, ErrNotFound
+	}
+	key, ok := s.keyring.Key(keyID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, ErrNotFound
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	ret := &Grant{}
+	if err = json.Unmarshal(plain, ret); err != nil {
+		return nil, ErrNotFound
+	}
+	return ret, nil
+}
+
+func newAEAD(key *CookieKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func grantKey(id string) string { return "grant:" + id }
+
+func familyKey(id string) string { return "family:" + id }
+
+func rotatedKey(id string) string { return "rotated:" + id }
+
+// NewCookieStore creates a CookieStore with given TTL settings
+func NewCookieStore(keyring CookieKeyring, idleTTL, maxTTL, rotateGrace time.Duration, options ...CookieStoreOption) *CookieStore {
+	ret := &CookieStore{
+		keyring:     keyring,
+		revocations: NewMemoryRevocationList(),
+		idleTTL:     idleTTL,
+		maxTTL:      maxTTL,
+		rotateGrace: rotateGrace,
+	}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
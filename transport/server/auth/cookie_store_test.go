@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookieStore_Get(t *testing.T) {
+	ctx := context.Background()
+	oldKey := &CookieKey{ID: "k1", Secret: bytes.Repeat([]byte("a"), 32)}
+	newKey := &CookieKey{ID: "k2", Secret: bytes.Repeat([]byte("b"), 32)}
+	var testCases = []struct {
+		description string
+		grant       *Grant
+		sealed      func(keyring *StaticCookieKeyring, id string) string
+		expectErr   error
+	}{
+		{description: "sealed with current key", grant: NewGrant("alice")},
+		{description: "sealed with rotated key", grant: NewGrant("alice"), sealed: func(keyring *StaticCookieKeyring, id string) string {
+			_ = keyring.Rotate(newKey)
+			return id
+		}},
+		{description: "sealed with retired key", grant: NewGrant("alice"), expectErr: ErrNotFound, sealed: func(keyring *StaticCookieKeyring, id string) string {
+			_ = keyring.Rotate(newKey)
+			keyring.Retire(oldKey.ID)
+			return id
+		}},
+		{description: "tampered ciphertext", grant: NewGrant("alice"), expectErr: ErrNotFound, sealed: func(keyring *StaticCookieKeyring, id string) string {
+			data := []byte(id)
+			data[len(data)-5] ^= 1
+			return string(data)
+		}},
+		{description: "swapped key id", grant: NewGrant("alice"), expectErr: ErrNotFound, sealed: func(keyring *StaticCookieKeyring, id string) string {
+			_ = keyring.Rotate(&CookieKey{ID: "k3", Secret: oldKey.Secret})
+			return "k3" + strings.TrimPrefix(id, "k1")
+		}},
+		{description: "idle expired", grant: &Grant{FamilyID: "f", Subject: "alice", ExpiresAt: time.Now().Add(-time.Second)}, expectErr: ErrNotFound},
+		{description: "absolute expired", grant: &Grant{FamilyID: "f", Subject: "alice", MaxExpiresAt: time.Now().Add(-time.Second)}, expectErr: ErrNotFound},
+		{description: "malformed", grant: NewGrant("alice"), expectErr: ErrNotFound, sealed: func(keyring *StaticCookieKeyring, id string) string {
+			return "not-sealed"
+		}},
+	}
+	for _, testCase := range testCases {
+		keyring, err := NewCookieKeyring(oldKey)
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		store := NewCookieStore(keyring, time.Hour, 24*time.Hour, 0)
+		assert.Nil(t, store.Put(ctx, testCase.grant), testCase.description)
+		assert.NotContains(t, testCase.grant.ID, "alice", testCase.description)
+		id := testCase.grant.ID
+		if testCase.sealed != nil {
+			id = testCase.sealed(keyring, id)
+		}
+		actual, err := store.Get(ctx, id)
+		if testCase.expectErr != nil {
+			assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+			continue
+		}
+		if assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, id, actual.ID, testCase.description)
+			assert.Equal(t, "alice", actual.Subject, testCase.description)
+		}
+	}
+}
+
+func TestCookieStore_Rotate(t *testing.T) {
+	ctx := context.Background()
+	keyring, _ := NewCookieKeyring(&CookieKey{ID: "k1", Secret: make([]byte, 32)})
+	store := NewCookieStore(keyring, time.Minute, time.Hour, time.Minute)
+	grant := NewGrant("alice")
+	grant.ExpiresAt = time.Now().Add(time.Second)
+	assert.Nil(t, store.Put(ctx, grant))
+	original, _ := store.Get(ctx, grant.ID)
+
+	newID, err := store.Rotate(ctx, grant.ID, &Grant{Subject: "alice"})
+	if !assert.Nil(t, err) {
+		return
+	}
+	rotated, err := store.Get(ctx, newID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, original.FamilyID, rotated.FamilyID)
+		assert.Equal(t, original.MaxExpiresAt.Unix(), rotated.MaxExpiresAt.Unix(), "absolute expiry is kept")
+		assert.True(t, rotated.ExpiresAt.After(original.ExpiresAt), "idle expiry slides")
+	}
+}
+
+func TestCookieStore_SharedRevocationList(t *testing.T) {
+	ctx := context.Background()
+	keyring, _ := NewCookieKeyring(&CookieKey{ID: "k1", Secret: make([]byte, 32)})
+	revocations := NewMemoryRevocationList()
+	replica1 := NewCookieStore(keyring, time.Hour, 24*time.Hour, 0, WithRevocationList(revocations))
+	replica2 := NewCookieStore(keyring, time.Hour, 24*time.Hour, 0, WithRevocationList(revocations))
+	grant := NewGrant("alice")
+	assert.Nil(t, replica1.Put(ctx, grant))
+	_, err := replica2.Get(ctx, grant.ID)
+	assert.Nil(t, err, "stateless: any replica opens the grant")
+
+	assert.Nil(t, replica1.RevokeFamily(ctx, grant.FamilyID))
+	_, err = replica2.Get(ctx, grant.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
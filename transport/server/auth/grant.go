@@ -1,8 +1,11 @@
 package auth
 
 import (
-	"github.com/google/uuid"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Grant represents a durable BFF authentication grant held server-side.
@@ -47,3 +50,13 @@ func NewGrant(subject string) *Grant {
 		LastUsedAt: now,
 	}
 }
+
+// GrantIDHash returns the SHA-256 hex digest of a grant id; audit records carry it instead of the id,
+// which is the live cookie credential (the whole sealed grant with CookieStore).
+func GrantIDHash(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// JWK represents a JSON Web Key (RSA keys are supported)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// RSAKeys returns RSA public keys of the set keyed by kid
+func (s *JWKS) RSAKeys() (map[string]*rsa.PublicKey, error) {
+	ret := map[string]*rsa.PublicKey{}
+	for _, key := range s.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk %v modulus: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk %v exponent: %w", key.Kid, err)
+		}
+		ret[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return ret, nil
+}
+
+// NewRSAJWK creates JWK for RSA public key
+func NewRSAJWK(kid string, key *rsa.PublicKey) *JWK {
+	return &JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// ParseJWKS parses JSON Web Key Set
+func ParseJWKS(data []byte) (*JWKS, error) {
+	ret := &JWKS{}
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+	return ret, nil
+}
+
+// LoadJWKS reads JSON Web Key Set from a local file
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWTVerifier verifies HS256 and RS256 signed JWT access tokens.
+type JWTVerifier struct {
+	secret    []byte
+	keys      map[string]*rsa.PublicKey
+	issuer    string
+	leeway    time.Duration
+	now       func() time.Time
+	jwksError error
+	// optionalExpiry accepts tokens without exp claim
+	optionalExpiry bool
+}
+
+// JWTOption represents JWT verifier option
+type JWTOption func(v *JWTVerifier)
+
+// WithHMACSecret enables HS256 tokens signed with secret
+func WithHMACSecret(secret []byte) JWTOption {
+	return func(v *JWTVerifier) { v.secret = secret }
+}
+
+// WithJWKS enables RS256 tokens signed with keys of the set
+func WithJWKS(jwks *JWKS) JWTOption {
+	return func(v *JWTVerifier) {
+		keys, err := jwks.RSAKeys()
+		if err != nil {
+			v.jwksError = err
+			return
+		}
+		for kid, key := range keys {
+			v.keys[kid] = key
+		}
+	}
+}
+
+// WithRSAPublicKey enables RS256 tokens signed with key (kid may be empty)
+func WithRSAPublicKey(kid string, key *rsa.PublicKey) JWTOption {
+	return func(v *JWTVerifier) { v.keys[kid] = key }
+}
+
+// WithIssuer requires the iss claim to match issuer
+func WithIssuer(issuer string) JWTOption {
+	return func(v *JWTVerifier) { v.issuer = issuer }
+}
+
+// WithLeeway sets clock skew tolerance for exp and nbf claims (default: 1 minute)
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(v *JWTVerifier) { v.leeway = leeway }
+}
+
+// WithOptionalExpiry accepts tokens without exp claim; by default they are rejected, as access tokens must expire (RFC 9068)
+func WithOptionalExpiry() JWTOption {
+	return func(v *JWTVerifier) { v.optionalExpiry = true }
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Verify verifies token signature and time claims and returns its principal
+func (v *JWTVerifier) Verify(_ context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed jwt", ErrInvalidToken)
+	}
+	header := &jwtHeader{}
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err = v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+	claims := map[string]any{}
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	principal := newPrincipal(claims)
+	now := v.now()
+	if principal.ExpiresAt.IsZero() && !v.optionalExpiry {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if !principal.ExpiresAt.IsZero() && now.After(principal.ExpiresAt.Add(v.leeway)) {
+		return nil, fmt.Errorf("%w: expired at %v", ErrTokenExpired, principal.ExpiresAt)
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.leeway).Before(nbf) {
+		return nil, fmt.Errorf("%w: not valid before %v", ErrTokenExpired, nbf)
+	}
+	if v.issuer != "" && principal.Issuer != v.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %v", ErrInvalidToken, principal.Issuer)
+	}
+	return principal, nil
+}
+
+func (v *JWTVerifier) verifySignature(header *jwtHeader, signed string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return fmt.Errorf("%w: HS256 is not enabled", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+	case "RS256":
+		key, ok := v.keys[header.Kid]
+		if !ok {
+			return fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, header.Kid)
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+}
+
+func decodeSegment(segment string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err = json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
+
+// newPrincipal creates principal from JWT claims
+func newPrincipal(claims map[string]any) *Principal {
+	ret := &Principal{Claims: claims}
+	ret.Subject, _ = claims["sub"].(string)
+	ret.Issuer, _ = claims["iss"].(string)
+	ret.ExpiresAt, _ = numericDate(claims["exp"])
+	ret.Audience = stringList(claims["aud"])
+	if scope, ok := claims["scope"].(string); ok {
+		ret.Scopes = strings.Fields(scope)
+	} else {
+		ret.Scopes = stringList(claims["scp"])
+	}
+	return ret
+}
+
+func numericDate(value any) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+func stringList(value any) []string {
+	switch actual := value.(type) {
+	case string:
+		return []string{actual}
+	case []any:
+		var ret []string
+		for _, item := range actual {
+			if text, ok := item.(string); ok {
+				ret = append(ret, text)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+// NewJWTVerifier creates JWT verifier; at least one of WithHMACSecret, WithJWKS or WithRSAPublicKey is required
+func NewJWTVerifier(options ...JWTOption) (*JWTVerifier, error) {
+	ret := &JWTVerifier{keys: map[string]*rsa.PublicKey{}, leeway: time.Minute, now: time.Now}
+	for _, option := range options {
+		option(ret)
+	}
+	if ret.jwksError != nil {
+		return nil, ret.jwksError
+	}
+	if len(ret.secret) == 0 && len(ret.keys) == 0 {
+		return nil, fmt.Errorf("jwt verifier requires HMAC secret or RSA keys")
+	}
+	return ret, nil
+}
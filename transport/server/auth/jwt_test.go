@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeSegment(value any) string {
+	data, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(secret []byte, claims map[string]any) string {
+	signed := encodeSegment(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signed := encodeSegment(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	secret := []byte("s3cret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.Nil(t, err) {
+		return
+	}
+	jwks, _ := json.Marshal(&JWKS{Keys: []*JWK{NewRSAJWK("k1", &rsaKey.PublicKey)}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.Nil(t, os.WriteFile(path, jwks, 0o600))
+	keySet, err := LoadJWKS(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	verifier, err := NewJWTVerifier(WithHMACSecret(secret), WithJWKS(keySet), WithIssuer("https://issuer.example"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	claims := map[string]any{"sub": "alice", "iss": "https://issuer.example", "exp": exp, "scope": "tools:read tools:call", "aud": "mcp"}
+
+	var testCases = []struct {
+		description string
+		token       string
+		expectErr   error
+	}{
+		{description: "hs256", token: signHS256(secret, claims)},
+		{description: "rs256 from jwks file", token: signRS256(rsaKey, "k1", claims)},
+		{description: "hs256 wrong secret", token: signHS256([]byte("other"), claims), expectErr: ErrInvalidToken},
+		{description: "rs256 unknown kid", token: signRS256(rsaKey, "k2", claims), expectErr: ErrInvalidToken},
+		{description: "expired", token: signHS256(secret, map[string]any{"sub": "alice", "iss": "https://issuer.example", "exp": float64(time.Now().Add(-time.Hour).Unix())}), expectErr: ErrTokenExpired},
+		{description: "not yet valid", token: signHS256(secret, map[string]any{"sub": "alice", "iss": "https://issuer.example", "exp": exp, "nbf": float64(time.Now().Add(time.Hour).Unix())}), expectErr: ErrTokenExpired},
+		{description: "missing exp", token: signHS256(secret, map[string]any{"sub": "alice", "iss": "https://issuer.example"}), expectErr: ErrInvalidToken},
+		{description: "wrong issuer", token: signHS256(secret, map[string]any{"sub": "alice", "iss": "https://evil.example", "exp": exp}), expectErr: ErrInvalidToken},
+		{description: "alg none", token: encodeSegment(map[string]string{"alg": "none"}) + "." + encodeSegment(claims) + ".", expectErr: ErrInvalidToken},
+		{description: "malformed", token: "abc", expectErr: ErrInvalidToken},
+	}
+	for _, testCase := range testCases {
+		principal, err := verifier.Verify(context.Background(), testCase.token)
+		if testCase.expectErr != nil {
+			assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, "alice", principal.Subject, testCase.description)
+		assert.Equal(t, []string{"tools:read", "tools:call"}, principal.Scopes, testCase.description)
+		assert.Equal(t, []string{"mcp"}, principal.Audience, testCase.description)
+		assert.True(t, principal.HasScope("tools:call"), testCase.description)
+	}
+
+	optional, err := NewJWTVerifier(WithHMACSecret(secret), WithOptionalExpiry())
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = optional.Verify(context.Background(), signHS256(secret, map[string]any{"sub": "alice"}))
+	assert.Nil(t, err, "exp is optional when opted out")
+}
+
+func TestBearerAuthenticator_Challenge(t *testing.T) {
+	authenticator := NewBearerAuthenticator(TokenVerifierFunc(func(ctx context.Context, token string) (*Principal, error) {
+		if token == "good" {
+			return &Principal{Subject: "alice"}, nil
+		}
+		return nil, ErrInvalidToken
+	}), WithRealm("api"))
+
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	_, err := authenticator.Authenticate(r)
+	assert.ErrorIs(t, err, ErrMissingToken)
+	w := httptest.NewRecorder()
+	authenticator.Challenge(w, r, err)
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+
+	r.Header.Set("Authorization", "Bearer bad")
+	_, err = authenticator.Authenticate(r)
+	w = httptest.NewRecorder()
+	authenticator.Challenge(w, r, err)
+	assert.True(t, strings.HasPrefix(w.Header().Get("WWW-Authenticate"), `Bearer realm="api", error="invalid_token"`))
+
+	r.Header.Set("Authorization", "bearer good")
+	principal, err := authenticator.Authenticate(r)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+}
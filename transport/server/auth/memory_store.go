@@ -6,15 +6,33 @@ import (
 	"time"
 )
 
+const (
+	// rotationPurgeInterval bounds how often rotation tombstones are scanned for expiry
+	rotationPurgeInterval = time.Minute
+	// defaultRotationRetention keeps tombstones when neither idle nor max TTL bounds the rotated grant
+	defaultRotationRetention = 24 * time.Hour
+)
+
 // MemoryStore is an in-memory AuthStore for development and tests.
-// It supports sliding idle TTL and absolute max TTL semantics.
+// It supports sliding idle TTL and absolute max TTL semantics, and detects reuse of rotated grant ids.
 type MemoryStore struct {
 	mux         sync.RWMutex
 	byID        map[string]*Grant
 	byFamily    map[string]map[string]struct{}
+	rotated     map[string]*rotation
 	idleTTL     time.Duration
 	maxTTL      time.Duration
 	rotateGrace time.Duration
+	hook        SecurityHook
+	nextPurge   time.Time
+}
+
+// rotation is a tombstone of a rotated grant id
+type rotation struct {
+	familyID   string
+	subject    string
+	graceUntil time.Time
+	keepUntil  time.Time
 }
 
 // NewMemoryStore creates a MemoryStore with given TTL settings.
@@ -22,6 +40,7 @@ func NewMemoryStore(idleTTL, maxTTL, rotateGrace time.Duration) *MemoryStore {
 	return &MemoryStore{
 		byID:        map[string]*Grant{},
 		byFamily:    map[string]map[string]struct{}{},
+		rotated:     map[string]*rotation{},
 		idleTTL:     idleTTL,
 		maxTTL:      maxTTL,
 		rotateGrace: rotateGrace,
@@ -44,6 +63,7 @@ func (s *MemoryStore) Put(_ context.Context, g *Grant) error {
 	if g.MaxExpiresAt.IsZero() && s.maxTTL > 0 {
 		g.MaxExpiresAt = now.Add(s.maxTTL)
 	}
+	s.purgeRotations(now)
 	s.byID[g.ID] = cloneGrant(g)
 	fam := s.byFamily[g.FamilyID]
 	if fam == nil {
@@ -54,21 +74,52 @@ func (s *MemoryStore) Put(_ context.Context, g *Grant) error {
 	return nil
 }
 
-func (s *MemoryStore) Get(_ context.Context, id string) (*Grant, error) {
+// SetSecurityHook sets hook receiving security events (e.g. GrantReused)
+func (s *MemoryStore) SetSecurityHook(hook SecurityHook) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.hook = hook
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Grant, error) {
+	now := time.Now()
+	s.mux.RLock()
+	purge := !now.Before(s.nextPurge)
+	s.mux.RUnlock()
+	if purge {
+		s.mux.Lock()
+		s.purgeRotations(now)
+		s.mux.Unlock()
+	}
 	s.mux.RLock()
 	g, ok := s.byID[id]
+	tombstone := s.rotated[id]
 	s.mux.RUnlock()
+	if tombstone != nil && (!ok || now.After(tombstone.graceUntil)) {
+		return nil, s.reused(ctx, id, tombstone)
+	}
 	if !ok {
 		return nil, ErrNotFound
 	}
-	now := time.Now()
 	if (!g.ExpiresAt.IsZero() && now.After(g.ExpiresAt)) || (!g.MaxExpiresAt.IsZero() && now.After(g.MaxExpiresAt)) {
-		_ = s.Revoke(context.Background(), id)
+		_ = s.Revoke(ctx, id)
 		return nil, ErrNotFound
 	}
 	return cloneGrant(g), nil
 }
 
+// reused revokes the family of a rotated id presented after its grace window and emits GrantReused
+func (s *MemoryStore) reused(ctx context.Context, id string, tombstone *rotation) error {
+	s.mux.Lock()
+	s.revokeFamily(tombstone.familyID)
+	hook := s.hook
+	s.mux.Unlock()
+	if hook != nil {
+		hook(ctx, &SecurityEvent{Type: GrantReused, Time: time.Now(), GrantIDHash: GrantIDHash(id), FamilyID: tombstone.familyID, Subject: tombstone.subject})
+	}
+	return ErrGrantReused
+}
+
 func (s *MemoryStore) Touch(_ context.Context, id string, at time.Time) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
@@ -76,6 +127,9 @@ func (s *MemoryStore) Touch(_ context.Context, id string, at time.Time) error {
 	if !ok {
 		return ErrNotFound
 	}
+	if now := time.Now(); (!g.ExpiresAt.IsZero() && now.After(g.ExpiresAt)) || (!g.MaxExpiresAt.IsZero() && now.After(g.MaxExpiresAt)) {
+		return ErrNotFound
+	}
 	g.LastUsedAt = at
 	if s.idleTTL > 0 {
 		newExp := at.Add(s.idleTTL)
@@ -87,7 +141,10 @@ func (s *MemoryStore) Touch(_ context.Context, id string, at time.Time) error {
 	return nil
 }
 
-func (s *MemoryStore) Rotate(_ context.Context, oldID string, newGrant *Grant) (string, error) {
+func (s *MemoryStore) Rotate(ctx context.Context, oldID string, newGrant *Grant) (string, error) {
+	if _, err := s.Get(ctx, oldID); err != nil {
+		return "", err
+	}
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	old, ok := s.byID[oldID]
@@ -120,19 +177,70 @@ func (s *MemoryStore) Rotate(_ context.Context, oldID string, newGrant *Grant) (
 		s.byFamily[ng.FamilyID] = fam
 	}
 	fam[ng.ID] = struct{}{}
-	// set grace on old by extending ExpiresAt briefly
+	// old id stays valid for the grace window; presenting it afterwards is reuse
+	s.purgeRotations(now)
+	if _, ok := s.rotated[oldID]; !ok {
+		s.rotated[oldID] = &rotation{familyID: old.FamilyID, subject: old.Subject, graceUntil: now.Add(s.rotateGrace), keepUntil: s.rotationKeepUntil(old, now)}
+	}
 	if s.rotateGrace > 0 {
 		old.ExpiresAt = now.Add(s.rotateGrace)
+	} else {
+		s.delete(oldID)
 	}
 	return ng.ID, nil
 }
 
+// rotationKeepUntil returns how long a tombstone of rotated grant is kept: until the old id could no longer be used
+// had it not been rotated (idle TTL, capped by the absolute expiry), or a fixed retention window when neither TTL is set
+func (s *MemoryStore) rotationKeepUntil(old *Grant, now time.Time) time.Time {
+	retention := s.idleTTL
+	if retention <= 0 {
+		retention = defaultRotationRetention
+	}
+	ret := now.Add(retention)
+	if !old.MaxExpiresAt.IsZero() && old.MaxExpiresAt.Before(ret) {
+		ret = old.MaxExpiresAt
+	}
+	if graceUntil := now.Add(s.rotateGrace); ret.Before(graceUntil) {
+		ret = graceUntil
+	}
+	return ret
+}
+
+// purgeRotations drops expired tombstones at most once per rotationPurgeInterval; caller must hold the write lock
+func (s *MemoryStore) purgeRotations(now time.Time) {
+	if now.Before(s.nextPurge) {
+		return
+	}
+	s.nextPurge = now.Add(rotationPurgeInterval)
+	for id, tombstone := range s.rotated {
+		if now.After(tombstone.keepUntil) {
+			delete(s.rotated, id)
+		}
+	}
+}
+
 func (s *MemoryStore) Revoke(_ context.Context, id string) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
+	if _, ok := s.byID[id]; !ok {
+		return ErrNotFound
+	}
+	s.delete(id)
+	return nil
+}
+
+func (s *MemoryStore) RevokeFamily(_ context.Context, familyID string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.revokeFamily(familyID)
+	return nil
+}
+
+func (s *MemoryStore) delete(id string) {
 	g, ok := s.byID[id]
 	if !ok {
-		return ErrNotFound
+		return
 	}
 	delete(s.byID, id)
 	if fam := s.byFamily[g.FamilyID]; fam != nil {
@@ -141,21 +249,13 @@ func (s *MemoryStore) Revoke(_ context.Context, id string) error {
 			delete(s.byFamily, g.FamilyID)
 		}
 	}
-	return nil
 }
 
-func (s *MemoryStore) RevokeFamily(_ context.Context, familyID string) error {
-	s.mux.Lock()
-	defer s.mux.Unlock()
-	fam := s.byFamily[familyID]
-	if fam == nil {
-		return nil
-	}
-	for id := range fam {
+func (s *MemoryStore) revokeFamily(familyID string) {
+	for id := range s.byFamily[familyID] {
 		delete(s.byID, id)
 	}
 	delete(s.byFamily, familyID)
-	return nil
 }
 
 func cloneGrant(g *Grant) *Grant {
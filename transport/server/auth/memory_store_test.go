@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_RotationTombstones(t *testing.T) {
+	ctx := context.Background()
+	var testCases = []struct {
+		description string
+		idleTTL     time.Duration
+		maxTTL      time.Duration
+		expectKeep  time.Duration
+	}{
+		{description: "idle ttl without max ttl", idleTTL: time.Hour, expectKeep: time.Hour},
+		{description: "max ttl caps idle ttl", idleTTL: time.Hour, maxTTL: 10 * time.Minute, expectKeep: 10 * time.Minute},
+		{description: "no ttl uses fixed retention", expectKeep: defaultRotationRetention},
+	}
+	for _, testCase := range testCases {
+		store := NewMemoryStore(testCase.idleTTL, testCase.maxTTL, 0)
+		grant := NewGrant("alice")
+		assert.Nil(t, store.Put(ctx, grant), testCase.description)
+		started := time.Now()
+		_, err := store.Rotate(ctx, grant.ID, NewGrant("alice"))
+		assert.Nil(t, err, testCase.description)
+		tombstone := store.rotated[grant.ID]
+		if !assert.NotNil(t, tombstone, testCase.description) {
+			continue
+		}
+		assert.WithinDuration(t, started.Add(testCase.expectKeep), tombstone.keepUntil, time.Second, testCase.description)
+
+		// tombstones past retention are purged from Get and Put, not only from Rotate
+		tombstone.keepUntil = time.Now().Add(-time.Second)
+		store.nextPurge = time.Time{}
+		_, err = store.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrNotFound, testCase.description)
+		assert.Len(t, store.rotated, 0, testCase.description)
+	}
+
+	store := NewMemoryStore(time.Hour, 0, 0)
+	store.rotated["stale"] = &rotation{keepUntil: time.Now().Add(-time.Second)}
+	assert.Nil(t, store.Put(ctx, NewGrant("bob")))
+	assert.Len(t, store.rotated, 0, "Put purges expired tombstones")
+}
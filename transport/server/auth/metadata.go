@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WellKnownProtectedResourcePath is the RFC 9728 protected resource metadata path
+const WellKnownProtectedResourcePath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata represents RFC 9728 OAuth 2.0 protected resource metadata
+type ProtectedResourceMetadata struct {
+	// Resource is the absolute https protected resource identifier (e.g. "https://api.example.com/mcp"); tokens must carry it as audience.
+	Resource string `json:"resource"`
+	// AuthorizationServers lists issuer identifiers of authorization servers accepted by the resource.
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+	JWKSURI                string   `json:"jwks_uri,omitempty"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+	ResourceDocumentation  string   `json:"resource_documentation,omitempty"`
+}
+
+// Path returns metadata path: the well-known path with the resource path appended (RFC 9728 section 3.1)
+func (m *ProtectedResourceMetadata) Path() string {
+	if parsed, err := url.Parse(m.Resource); err == nil {
+		if resourcePath := strings.TrimSuffix(parsed.EscapedPath(), "/"); resourcePath != "" {
+			return WellKnownProtectedResourcePath + resourcePath
+		}
+	}
+	return WellKnownProtectedResourcePath
+}
+
+// Validate returns an error unless Resource is an absolute https URL without query or fragment (RFC 9728 section 2)
+func (m *ProtectedResourceMetadata) Validate() error {
+	parsed, err := url.Parse(m.Resource)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("invalid protected resource %q: must be an absolute https URL without query or fragment", m.Resource)
+	}
+	return nil
+}
+
+// URL returns absolute metadata URL derived from Resource; it is empty when the metadata is not valid
+func (m *ProtectedResourceMetadata) URL() string {
+	if m.Validate() != nil {
+		return ""
+	}
+	parsed, _ := url.Parse(m.Resource)
+	return parsed.Scheme + "://" + parsed.Host + m.Path()
+}
+
+// Matches returns true if request path addresses the metadata document
+func (m *ProtectedResourceMetadata) Matches(r *http.Request) bool {
+	if m == nil || (r.Method != http.MethodGet && r.Method != http.MethodOptions) || m.Validate() != nil {
+		return false
+	}
+	return r.URL.Path == m.Path() || r.URL.Path == WellKnownProtectedResourcePath
+}
+
+// ServeHTTP serves the metadata document
+func (m *ProtectedResourceMetadata) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	document := *m
+	if len(document.BearerMethodsSupported) == 0 {
+		document.BearerMethodsSupported = []string{"header"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	_ = json.NewEncoder(w).Encode(&document)
+}
+
+// NewProtectedResourceMetadata creates metadata for an absolute https resource accepting tokens of authorizationServers
+func NewProtectedResourceMetadata(resource string, authorizationServers ...string) (*ProtectedResourceMetadata, error) {
+	ret := &ProtectedResourceMetadata{Resource: resource, AuthorizationServers: authorizationServers}
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
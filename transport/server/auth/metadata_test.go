@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedResourceMetadata_URL(t *testing.T) {
+	var testCases = []struct {
+		description string
+		resource    string
+		expectPath  string
+		expectURL   string
+		expectErr   bool
+	}{
+		{description: "absolute resource with path", resource: "https://api.example.com/mcp", expectPath: "/.well-known/oauth-protected-resource/mcp", expectURL: "https://api.example.com/.well-known/oauth-protected-resource/mcp"},
+		{description: "absolute resource without path", resource: "https://api.example.com", expectPath: "/.well-known/oauth-protected-resource", expectURL: "https://api.example.com/.well-known/oauth-protected-resource"},
+		{description: "relative resource", resource: "/mcp", expectPath: "/.well-known/oauth-protected-resource/mcp", expectErr: true},
+		{description: "empty resource", resource: "", expectPath: "/.well-known/oauth-protected-resource", expectErr: true},
+		{description: "plain http resource", resource: "http://api.example.com/mcp", expectPath: "/.well-known/oauth-protected-resource/mcp", expectErr: true},
+		{description: "resource with fragment", resource: "https://api.example.com/mcp#x", expectPath: "/.well-known/oauth-protected-resource/mcp", expectErr: true},
+	}
+	for _, testCase := range testCases {
+		metadata := &ProtectedResourceMetadata{Resource: testCase.resource}
+		assert.Equal(t, testCase.expectPath, metadata.Path(), testCase.description)
+		assert.Equal(t, testCase.expectURL, metadata.URL(), testCase.description)
+		_, err := NewProtectedResourceMetadata(testCase.resource)
+		assert.Equal(t, testCase.expectErr, err != nil, testCase.description)
+		assert.Equal(t, !testCase.expectErr, metadata.Matches(httptest.NewRequest("GET", metadata.Path(), nil)), testCase.description)
+	}
+}
+
+func TestBearerAuthenticator_With(t *testing.T) {
+	metadata := &ProtectedResourceMetadata{Resource: "https://api.example.com/mcp"}
+	shared := NewBearerAuthenticator(TokenVerifierFunc(func(ctx context.Context, token string) (*Principal, error) {
+		return &Principal{Subject: "alice"}, nil
+	}))
+	copied := shared.With(WithResourceMetadata(metadata))
+	assert.Nil(t, shared.Metadata(), "receiver is not modified")
+	assert.Equal(t, metadata, copied.Metadata())
+}
+
+func TestProtectedResourceMetadata_ServeHTTP(t *testing.T) {
+	metadata := &ProtectedResourceMetadata{Resource: "https://api.example.com/mcp", AuthorizationServers: []string{"https://issuer.example"}, ScopesSupported: []string{"tools:call"}}
+	r := httptest.NewRequest("GET", "/.well-known/oauth-protected-resource/mcp", nil)
+	assert.True(t, metadata.Matches(r))
+	assert.False(t, metadata.Matches(httptest.NewRequest("POST", "/.well-known/oauth-protected-resource/mcp", nil)))
+	assert.False(t, metadata.Matches(httptest.NewRequest("GET", "/mcp", nil)))
+	w := httptest.NewRecorder()
+	metadata.ServeHTTP(w, r)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	document := map[string]any{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "https://api.example.com/mcp", document["resource"])
+	assert.Equal(t, []any{"https://issuer.example"}, document["authorization_servers"])
+	assert.Equal(t, []any{"header"}, document["bearer_methods_supported"])
+}
+
+func TestBearerAuthenticator_Audience(t *testing.T) {
+	verifier := TokenVerifierFunc(func(ctx context.Context, token string) (*Principal, error) {
+		return &Principal{Subject: "alice", Audience: []string{token}}, nil
+	})
+	metadata := &ProtectedResourceMetadata{Resource: "https://api.example.com/mcp"}
+	var testCases = []struct {
+		description string
+		options     []BearerOption
+		audience    string
+		expectErr   bool
+	}{
+		{description: "no audience check", audience: "other"},
+		{description: "resource audience", options: []BearerOption{WithResourceMetadata(metadata)}, audience: "https://api.example.com/mcp"},
+		{description: "foreign audience", options: []BearerOption{WithResourceMetadata(metadata)}, audience: "https://other.example.com", expectErr: true},
+		{description: "explicit audience overrides resource", options: []BearerOption{WithResourceMetadata(metadata), WithAudience("mcp")}, audience: "mcp"},
+	}
+	for _, testCase := range testCases {
+		authenticator := NewBearerAuthenticator(verifier, testCase.options...)
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		r.Header.Set("Authorization", "Bearer "+testCase.audience)
+		_, err := authenticator.Authenticate(r)
+		if testCase.expectErr {
+			assert.ErrorIs(t, err, ErrInvalidToken, testCase.description)
+			continue
+		}
+		assert.Nil(t, err, testCase.description)
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/viant/jsonrpc"
+)
+
+// Principal represents the identity authenticated from a bearer token.
+type Principal struct {
+	// Subject identifies the authenticated principal (sub claim).
+	Subject string `json:"subject"`
+	// Issuer identifies the token issuer (iss claim).
+	Issuer string `json:"issuer,omitempty"`
+	// Audience lists token audiences (aud claim).
+	Audience []string `json:"audience,omitempty"`
+	// Scopes granted to the token (scope or scp claim).
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresAt is the token expiry (exp claim).
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	// Claims holds all token claims.
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// Fingerprint returns a stable identifier of the principal (issuer and subject) used to bind sessions;
+// a refreshed token of the same principal yields the same fingerprint.
+func (p *Principal) Fingerprint() string {
+	if p == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(p.Issuer + "\x00" + p.Subject))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope returns true if principal was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, candidate := range p.Scopes {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey string
+
+const principalContextKey = principalKey("jsonrpc-auth-principal")
+
+// WithPrincipal returns context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+// PrincipalFromContext returns the principal authenticated by the transport for the current request.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if info, ok := jsonrpc.RequestInfoFromContext(ctx); ok {
+		if p, ok := info.Principal.(*Principal); ok && p != nil {
+			return p, true
+		}
+	}
+	p, ok := ctx.Value(principalContextKey).(*Principal)
+	return p, ok && p != nil
+}
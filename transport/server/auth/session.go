@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+// principalAttribute holds the fingerprint of the principal a session is bound to
+const principalAttribute = base.AttributeKey[string]("jsonrpc.auth.principal")
+
+// BindSession binds session to principal on first use; it returns false when the session is bound
+// to a different principal. A nil principal (authentication disabled) is not checked.
+func BindSession(aSession *base.Session, principal *Principal) bool {
+	if principal == nil {
+		return true
+	}
+	fingerprint := principal.Fingerprint()
+	bound, loaded := principalAttribute.LoadOrStore(aSession, fingerprint)
+	return !loaded || bound == fingerprint
+}
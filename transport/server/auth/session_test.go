@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+func TestBindSession_Concurrent(t *testing.T) {
+	aSession := &base.Session{Id: "s1"}
+	var bound int32
+	var group sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			if BindSession(aSession, &Principal{Subject: fmt.Sprintf("user-%d", i)}) {
+				atomic.AddInt32(&bound, 1)
+			}
+		}(i)
+	}
+	group.Wait()
+	assert.EqualValues(t, 1, bound, "session is bound to exactly one principal")
+	assert.True(t, BindSession(aSession, nil), "nil principal is not checked")
+}
@@ -9,6 +9,9 @@ import (
 var (
 	// ErrNotFound indicates no grant was found for the given id.
 	ErrNotFound = errors.New("auth grant not found")
+	// ErrGrantReused indicates a rotated grant id was presented after its grace window (e.g. a replayed stolen cookie);
+	// the store has revoked the whole grant family.
+	ErrGrantReused = errors.New("auth grant reused after rotation")
 )
 
 // Store defines the contract for a durable BFF authentication grant store.
@@ -19,6 +22,7 @@ type Store interface {
 	Put(ctx context.Context, g *Grant) error
 
 	// Get retrieves a grant by id. Should return ErrNotFound if missing or expired.
+	// A rotated id presented after its grace window must revoke the grant family and return ErrGrantReused.
 	Get(ctx context.Context, id string) (*Grant, error)
 
 	// Touch updates last-used timestamp and extends idle expiry (sliding TTL) as appropriate.
@@ -26,6 +30,7 @@ type Store interface {
 
 	// Rotate atomically replaces an existing grant id with a new one.
 	// Returns the new id; implementations may keep the old id valid for a short grace window.
+	// Rotating an id already rotated past its grace window is reuse: revoke the family and return ErrGrantReused.
 	Rotate(ctx context.Context, oldID string, newGrant *Grant) (string, error)
 
 	// Revoke deletes a specific grant id immediately.
@@ -34,3 +39,27 @@ type Store interface {
 	// RevokeFamily deletes all grants in the same family (logout-all across devices/tabs).
 	RevokeFamily(ctx context.Context, familyID string) error
 }
+
+// SecurityEventType identifies a security event
+type SecurityEventType string
+
+// GrantReused is emitted when a rotated grant id is presented after its grace window
+const GrantReused SecurityEventType = "grant_reused"
+
+// SecurityEvent represents a security relevant store event
+type SecurityEvent struct {
+	Type SecurityEventType `json:"type"`
+	Time time.Time         `json:"time"`
+	// GrantIDHash is GrantIDHash of the presented grant id; the id itself is a credential and is never recorded
+	GrantIDHash string `json:"grantIdHash"`
+	FamilyID    string `json:"familyId"`
+	Subject     string `json:"subject,omitempty"`
+}
+
+// SecurityHook receives security events
+type SecurityHook func(ctx context.Context, event *SecurityEvent)
+
+// SecurityNotifier is implemented by stores emitting security events
+type SecurityNotifier interface {
+	SetSecurityHook(hook SecurityHook)
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrMissingToken indicates the request carries no bearer token.
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrInvalidToken indicates the token is malformed, has an invalid signature or claims.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired indicates the token is expired or not yet valid.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// TokenVerifier verifies a bearer token and returns its principal.
+// Implementations should wrap ErrInvalidToken or ErrTokenExpired so that challenges carry the right error code.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// TokenVerifierFunc adapts a function to TokenVerifier
+type TokenVerifierFunc func(ctx context.Context, token string) (*Principal, error)
+
+// Verify verifies token
+func (f TokenVerifierFunc) Verify(ctx context.Context, token string) (*Principal, error) {
+	return f(ctx, token)
+}
@@ -0,0 +1,203 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/viant/jsonrpc"
+)
+
+// Attributes represents a concurrency-safe bag of application state attached to a session
+// (e.g. client info, negotiated protocol version or capabilities).
+// Attributes are serialized together with the session so that custom SessionStore
+// implementations can persist them; they are released when the session is removed from the store.
+type Attributes struct {
+	mux    sync.RWMutex
+	values map[string]any
+}
+
+// Get returns attribute value
+func (a *Attributes) Get(key string) (any, bool) {
+	if a == nil {
+		return nil, false
+	}
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	value, ok := a.values[key]
+	return value, ok
+}
+
+// Set sets attribute value
+func (a *Attributes) Set(key string, value any) {
+	if a == nil {
+		return
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if a.values == nil {
+		a.values = map[string]any{}
+	}
+	a.values[key] = value
+}
+
+// LoadOrStore returns the existing attribute value if present (loaded is true); otherwise it stores value atomically
+func (a *Attributes) LoadOrStore(key string, value any) (actual any, loaded bool) {
+	if a == nil {
+		return value, false
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if existing, ok := a.values[key]; ok {
+		return existing, true
+	}
+	if a.values == nil {
+		a.values = map[string]any{}
+	}
+	a.values[key] = value
+	return value, false
+}
+
+// Delete removes attribute
+func (a *Attributes) Delete(key string) {
+	if a == nil {
+		return
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	delete(a.values, key)
+}
+
+// Len returns number of attributes
+func (a *Attributes) Len() int {
+	if a == nil {
+		return 0
+	}
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	return len(a.values)
+}
+
+// Range iterates over a snapshot of attributes, stopping when fn returns false
+func (a *Attributes) Range(fn func(key string, value any) bool) {
+	if a == nil {
+		return
+	}
+	a.mux.RLock()
+	snapshot := make(map[string]any, len(a.values))
+	for k, v := range a.values {
+		snapshot[k] = v
+	}
+	a.mux.RUnlock()
+	for k, v := range snapshot {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// Clear releases all attributes
+func (a *Attributes) Clear() {
+	if a == nil {
+		return
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.values = nil
+}
+
+// MarshalJSON marshals attributes for session persistence
+func (a *Attributes) MarshalJSON() ([]byte, error) {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+	if a.values == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(a.values)
+}
+
+// UnmarshalJSON restores persisted attributes; values are kept raw until accessed with a typed key
+func (a *Attributes) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.values = make(map[string]any, len(values))
+	for k, v := range values {
+		a.values[k] = v
+	}
+	return nil
+}
+
+// AttributeKey represents a typed session attribute key
+type AttributeKey[T any] string
+
+// Get returns typed attribute value from the session
+func (k AttributeKey[T]) Get(s *Session) (T, bool) {
+	var zero T
+	if s == nil {
+		return zero, false
+	}
+	value, ok := s.Attributes.Get(string(k))
+	if !ok {
+		return zero, false
+	}
+	if typed, ok := value.(T); ok {
+		return typed, true
+	}
+	// value restored from a persisted session: decode and cache typed value
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		return zero, false
+	}
+	var typed T
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return zero, false
+	}
+	s.Attributes.Set(string(k), typed)
+	return typed, true
+}
+
+// Set sets typed attribute value on the session
+func (k AttributeKey[T]) Set(s *Session, value T) {
+	if s == nil {
+		return
+	}
+	s.Attributes.Set(string(k), value)
+}
+
+// LoadOrStore returns the existing typed value if present (loaded is true); otherwise it stores value atomically
+func (k AttributeKey[T]) LoadOrStore(s *Session, value T) (actual T, loaded bool) {
+	if s == nil {
+		return value, false
+	}
+	existing, loaded := s.Attributes.LoadOrStore(string(k), value)
+	if !loaded {
+		return value, false
+	}
+	if typed, ok := existing.(T); ok {
+		return typed, true
+	}
+	// value restored from a persisted session
+	actual, _ = k.Get(s)
+	return actual, true
+}
+
+// Delete removes attribute from the session
+func (k AttributeKey[T]) Delete(s *Session) {
+	if s == nil {
+		return
+	}
+	s.Attributes.Delete(string(k))
+}
+
+// SessionFromContext returns the server session associated with the handler context
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	aSession, ok := ctx.Value(jsonrpc.SessionKey).(*Session)
+	return aSession, ok && aSession != nil
+}
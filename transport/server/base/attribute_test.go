@@ -0,0 +1,104 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+)
+
+type clientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func TestAttributeKey(t *testing.T) {
+	key := AttributeKey[*clientInfo]("clientInfo")
+	session := &Session{Id: "s1"}
+
+	_, ok := key.Get(session)
+	assert.False(t, ok)
+
+	key.Set(session, &clientInfo{Name: "cli", Version: "1.0"})
+	info, ok := key.Get(session)
+	assert.True(t, ok)
+	assert.Equal(t, "cli", info.Name)
+
+	key.Delete(session)
+	_, ok = key.Get(session)
+	assert.False(t, ok)
+}
+
+func TestAttributeKey_LoadOrStore(t *testing.T) {
+	key := AttributeKey[string]("owner")
+	session := &Session{Id: "s1"}
+	var stored int32
+	var group sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			if _, loaded := key.LoadOrStore(session, fmt.Sprintf("owner-%d", i)); !loaded {
+				atomic.AddInt32(&stored, 1)
+			}
+		}(i)
+	}
+	group.Wait()
+	assert.EqualValues(t, 1, stored, "exactly one value is stored")
+	owner, _ := key.Get(session)
+	actual, loaded := key.LoadOrStore(session, "other")
+	assert.True(t, loaded)
+	assert.Equal(t, owner, actual)
+
+	// value restored from a persisted session is decoded
+	restored := &Session{Id: "s2"}
+	assert.Nil(t, json.Unmarshal([]byte(`{"owner":"alice"}`), &restored.Attributes))
+	actual, loaded = key.LoadOrStore(restored, "bob")
+	assert.True(t, loaded)
+	assert.Equal(t, "alice", actual)
+}
+
+func TestAttributes_Persistence(t *testing.T) {
+	key := AttributeKey[*clientInfo]("clientInfo")
+	version := AttributeKey[string]("protocolVersion")
+	session := &Session{Id: "s1"}
+	key.Set(session, &clientInfo{Name: "cli", Version: "1.0"})
+	version.Set(session, "2025-06-18")
+
+	data, err := json.Marshal(&session.Attributes)
+	assert.Nil(t, err)
+
+	restored := &Session{Id: "s1"}
+	assert.Nil(t, json.Unmarshal(data, &restored.Attributes))
+	info, ok := key.Get(restored)
+	assert.True(t, ok)
+	assert.EqualValues(t, &clientInfo{Name: "cli", Version: "1.0"}, info)
+	actual, ok := version.Get(restored)
+	assert.True(t, ok)
+	assert.Equal(t, "2025-06-18", actual)
+}
+
+func TestMemorySessionStore_DeleteReleasesAttributes(t *testing.T) {
+	store := NewMemorySessionStore()
+	session := &Session{Id: "s1"}
+	session.Attributes.Set("k", "v")
+	store.Put(session.Id, session)
+	store.Delete(session.Id)
+	assert.Equal(t, 0, session.Attributes.Len())
+}
+
+func TestSessionFromContext(t *testing.T) {
+	_, ok := SessionFromContext(context.Background())
+	assert.False(t, ok)
+	_, ok = SessionFromContext(context.WithValue(context.Background(), jsonrpc.SessionKey, "stdio"))
+	assert.False(t, ok)
+	session := &Session{Id: "s1"}
+	actual, ok := SessionFromContext(context.WithValue(context.Background(), jsonrpc.SessionKey, session))
+	assert.True(t, ok)
+	assert.Equal(t, session, actual)
+}
@@ -0,0 +1,21 @@
+package base
+
+import (
+	"context"
+
+	"github.com/viant/jsonrpc"
+)
+
+// Authorizer decides whether an inbound request or notification may reach the session handler
+type Authorizer interface {
+	// Authorize returns a JSON-RPC error when method is not allowed in ctx (see jsonrpc.RequestInfoFromContext)
+	Authorize(ctx context.Context, method string) *jsonrpc.Error
+}
+
+// AuthorizerFunc adapts a function to Authorizer
+type AuthorizerFunc func(ctx context.Context, method string) *jsonrpc.Error
+
+// Authorize calls fn
+func (fn AuthorizerFunc) Authorize(ctx context.Context, method string) *jsonrpc.Error {
+	return fn(ctx, method)
+}
@@ -0,0 +1,321 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/viant/jsonrpc"
+)
+
+const (
+	// DefaultSubscribeMethod is the RPC method subscribing the calling session to a topic
+	DefaultSubscribeMethod = "subscribe"
+	// DefaultUnsubscribeMethod is the RPC method cancelling a subscription
+	DefaultUnsubscribeMethod = "unsubscribe"
+	// DefaultSubscriptionMethod is the notification method used to deliver subscription results
+	DefaultSubscriptionMethod = "subscription"
+)
+
+// Subscription represents a session subscription created with the subscribe RPC
+type Subscription struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"`
+	Topic     string `json:"topic"`
+}
+
+// SubscriptionResult represents params of subscription notification
+type SubscriptionResult struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result,omitempty"`
+}
+
+// Broker publishes notifications to sessions by topic, to all sessions or to a filtered set.
+// Delivery goes through each session's framing and replay buffer; memberships are dropped when
+// the session expires or is deleted.
+type Broker struct {
+	handler            *Handler
+	mux                sync.RWMutex
+	topics             map[string]map[string]bool // topic -> session ids
+	members            map[string]map[string]bool // session id -> topics
+	subscriptions      map[string]*Subscription
+	subscribers        map[string]map[string]map[string]bool // topic -> session id -> subscription ids
+	seq                uint64
+	subscribeMethod    string
+	unsubscribeMethod  string
+	subscriptionMethod string
+}
+
+// BrokerOption represents broker option
+type BrokerOption func(b *Broker)
+
+// WithSubscriptionMethods sets RPC method names used for subscribe, unsubscribe and subscription notification
+// (e.g. "eth_subscribe", "eth_unsubscribe", "eth_subscription").
+func WithSubscriptionMethods(subscribe, unsubscribe, notification string) BrokerOption {
+	return func(b *Broker) {
+		b.subscribeMethod = subscribe
+		b.unsubscribeMethod = unsubscribe
+		b.subscriptionMethod = notification
+	}
+}
+
+// Join adds session to topic
+func (b *Broker) Join(sessionID string, topics ...string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, topic := range topics {
+		b.add(b.topics, topic, sessionID)
+		b.add(b.members, sessionID, topic)
+	}
+}
+
+// Leave removes session from topic
+func (b *Broker) Leave(sessionID string, topics ...string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, topic := range topics {
+		b.remove(b.topics, topic, sessionID)
+		b.remove(b.members, sessionID, topic)
+	}
+}
+
+// Topics returns topics the session joined
+func (b *Broker) Topics(sessionID string) []string {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	var result []string
+	for topic := range b.members[sessionID] {
+		result = append(result, topic)
+	}
+	return result
+}
+
+// Subscribe creates subscription for session topic; topic notifications are delivered to the session
+// as subscription notifications carrying the returned id.
+func (b *Broker) Subscribe(sessionID, topic string) *Subscription {
+	ret := &Subscription{
+		ID:        fmt.Sprintf("0x%x", atomic.AddUint64(&b.seq, 1)),
+		SessionID: sessionID,
+		Topic:     topic,
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.subscriptions[ret.ID] = ret
+	b.add(b.topics, topic, sessionID)
+	sessions, ok := b.subscribers[topic]
+	if !ok {
+		sessions = map[string]map[string]bool{}
+		b.subscribers[topic] = sessions
+	}
+	b.add(sessions, sessionID, ret.ID)
+	return ret
+}
+
+// Unsubscribe removes session subscription; it returns false if the subscription does not exist
+func (b *Broker) Unsubscribe(sessionID, id string) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	subscription, ok := b.subscriptions[id]
+	if !ok || subscription.SessionID != sessionID {
+		return false
+	}
+	b.removeSubscription(subscription)
+	return true
+}
+
+// Publish sends notification to sessions of the topic; it returns number of sessions notified
+func (b *Broker) Publish(ctx context.Context, topic string, notification *jsonrpc.Notification) int {
+	b.mux.RLock()
+	var sessionIDs []string
+	for sessionID := range b.topics[topic] {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	b.mux.RUnlock()
+	delivered := 0
+	for _, sessionID := range sessionIDs {
+		aSession, ok := b.handler.Sessions.Get(sessionID)
+		if !ok {
+			b.Drop(sessionID)
+			continue
+		}
+		if b.publish(ctx, aSession, topic, notification) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// Broadcast sends notification to all sessions
+func (b *Broker) Broadcast(ctx context.Context, notification *jsonrpc.Notification) int {
+	return b.PublishFunc(ctx, notification, nil)
+}
+
+// PublishFunc sends notification to sessions matching filter (all sessions when filter is nil)
+func (b *Broker) PublishFunc(ctx context.Context, notification *jsonrpc.Notification, filter func(session *Session) bool) int {
+	delivered := 0
+	b.handler.Sessions.Range(func(id string, aSession *Session) bool {
+		if filter != nil && !filter(aSession) {
+			return true
+		}
+		if err := aSession.Notify(ctx, notification); err == nil {
+			delivered++
+		}
+		return true
+	})
+	return delivered
+}
+
+// publish delivers notification to joined session and its subscriptions
+func (b *Broker) publish(ctx context.Context, aSession *Session, topic string, notification *jsonrpc.Notification) bool {
+	b.mux.RLock()
+	joined := b.members[aSession.Id][topic]
+	var subscriptionIDs []string
+	for id := range b.subscribers[topic][aSession.Id] {
+		subscriptionIDs = append(subscriptionIDs, id)
+	}
+	b.mux.RUnlock()
+	delivered := false
+	if joined && aSession.Notify(ctx, notification) == nil {
+		delivered = true
+	}
+	for _, id := range subscriptionIDs {
+		params, err := json.Marshal(&SubscriptionResult{Subscription: id, Result: notification.Params})
+		if err != nil {
+			continue
+		}
+		if aSession.Notify(ctx, &jsonrpc.Notification{Jsonrpc: jsonrpc.Version, Method: b.subscriptionMethod, Params: params}) == nil {
+			delivered = true
+		}
+	}
+	return delivered
+}
+
+// Drop removes all session memberships and subscriptions
+func (b *Broker) Drop(sessionID string) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for topic := range b.members[sessionID] {
+		b.remove(b.topics, topic, sessionID)
+	}
+	delete(b.members, sessionID)
+	for _, subscription := range b.subscriptions {
+		if subscription.SessionID == sessionID {
+			b.removeSubscription(subscription)
+		}
+	}
+}
+
+// HandleRequest serves subscribe/unsubscribe RPC for the session in ctx; it returns false for other methods.
+// It is not dispatched automatically: the session handler must call it from Serve, so that it owns the method names.
+// Subscribe params: ["topic"] or {"topic":"..."}; result is subscription id.
+// Unsubscribe params: ["id"] or {"subscription":"..."}; result is a boolean.
+func (b *Broker) HandleRequest(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) bool {
+	if request.Method != b.subscribeMethod && request.Method != b.unsubscribeMethod {
+		return false
+	}
+	aSession, ok := SessionFromContext(ctx)
+	if !ok {
+		response.Error = jsonrpc.NewInternalError("session not found", nil)
+		return true
+	}
+	value, err := firstParam(request.Params, "topic", "subscription")
+	if err != nil || value == "" {
+		response.Error = jsonrpc.NewInvalidParamsError(fmt.Sprintf("invalid %v params", request.Method), request.Params)
+		return true
+	}
+	var result interface{}
+	if request.Method == b.subscribeMethod {
+		result = b.Subscribe(aSession.Id, value).ID
+	} else {
+		result = b.Unsubscribe(aSession.Id, value)
+	}
+	if response.Result, err = json.Marshal(result); err != nil {
+		response.Error = jsonrpc.NewInternalError(err.Error(), nil)
+	}
+	return true
+}
+
+// onSessionEvent drops memberships of removed sessions
+func (b *Broker) onSessionEvent(event *SessionEvent) {
+	switch event.Type {
+	case SessionExpired, SessionDeleted:
+		b.Drop(event.SessionID)
+	}
+}
+
+// removeSubscription removes subscription from the indexes, leaving the topic when the session neither joined it
+// nor holds another subscription to it; caller must hold the mutex.
+func (b *Broker) removeSubscription(subscription *Subscription) {
+	delete(b.subscriptions, subscription.ID)
+	sessions := b.subscribers[subscription.Topic]
+	if sessions == nil {
+		return
+	}
+	b.remove(sessions, subscription.SessionID, subscription.ID)
+	if len(sessions) == 0 {
+		delete(b.subscribers, subscription.Topic)
+	}
+	if !b.members[subscription.SessionID][subscription.Topic] && len(sessions[subscription.SessionID]) == 0 {
+		b.remove(b.topics, subscription.Topic, subscription.SessionID)
+	}
+}
+
+func (b *Broker) add(index map[string]map[string]bool, key, value string) {
+	values, ok := index[key]
+	if !ok {
+		values = map[string]bool{}
+		index[key] = values
+	}
+	values[value] = true
+}
+
+func (b *Broker) remove(index map[string]map[string]bool, key, value string) {
+	if values, ok := index[key]; ok {
+		delete(values, value)
+		if len(values) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+// firstParam returns first positional param or the first matching named param
+func firstParam(params []byte, names ...string) (string, error) {
+	var positional []string
+	if err := json.Unmarshal(params, &positional); err == nil {
+		if len(positional) == 0 {
+			return "", nil
+		}
+		return positional[0], nil
+	}
+	named := map[string]string{}
+	if err := json.Unmarshal(params, &named); err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if value, ok := named[name]; ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// NewBroker creates a broker over handler sessions
+func NewBroker(handler *Handler, options ...BrokerOption) *Broker {
+	ret := &Broker{
+		handler:            handler,
+		topics:             map[string]map[string]bool{},
+		members:            map[string]map[string]bool{},
+		subscriptions:      map[string]*Subscription{},
+		subscribers:        map[string]map[string]map[string]bool{},
+		subscribeMethod:    DefaultSubscribeMethod,
+		unsubscribeMethod:  DefaultUnsubscribeMethod,
+		subscriptionMethod: DefaultSubscriptionMethod,
+	}
+	for _, option := range options {
+		option(ret)
+	}
+	handler.Events.Subscribe(ret.onSessionEvent)
+	return ret
+}
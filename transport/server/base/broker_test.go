@@ -0,0 +1,111 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+func newBrokerSession(handler *Handler, id string) (*Session, *syncBuffer) {
+	writer := &syncBuffer{}
+	aSession := NewSession(context.Background(), id, writer, func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &nopHandler{}
+	}, WithSSE(), WithEventBuffer(16))
+	handler.Sessions.Put(id, aSession)
+	return aSession, writer
+}
+
+func TestBroker_Publish(t *testing.T) {
+	handler := NewHandler()
+	broker := NewBroker(handler)
+	s1, w1 := newBrokerSession(handler, "s1")
+	_, w2 := newBrokerSession(handler, "s2")
+	broker.Join("s1", "resources")
+
+	notification, _ := jsonrpc.NewNotification("notifications/resources/updated", map[string]string{"uri": "file:///a"})
+	assert.Equal(t, 1, broker.Publish(context.Background(), "resources", notification))
+	assert.Contains(t, w1.String(), "resources/updated")
+	assert.NotContains(t, w2.String(), "resources/updated")
+	// delivered through session framing and replay buffer
+	assert.True(t, strings.HasPrefix(w1.String(), "id: 1\n"))
+	count, _ := s1.BufferedEvents()
+	assert.Equal(t, 1, count)
+
+	list, _ := jsonrpc.NewNotification("notifications/tools/list_changed", nil)
+	assert.Equal(t, 2, broker.Broadcast(context.Background(), list))
+	assert.Equal(t, 1, broker.PublishFunc(context.Background(), list, func(s *Session) bool { return s.Id == "s2" }))
+
+	broker.Leave("s1", "resources")
+	assert.Equal(t, 0, broker.Publish(context.Background(), "resources", notification))
+}
+
+func TestBroker_Subscription(t *testing.T) {
+	handler := NewHandler()
+	broker := NewBroker(handler, WithSubscriptionMethods("eth_subscribe", "eth_unsubscribe", "eth_subscription"))
+	aSession, writer := newBrokerSession(handler, "s1")
+	ctx := context.WithValue(context.Background(), jsonrpc.SessionKey, aSession)
+
+	response := &jsonrpc.Response{}
+	assert.True(t, broker.HandleRequest(ctx, &jsonrpc.Request{Method: "eth_subscribe", Params: []byte(`["newHeads"]`)}, response))
+	assert.Nil(t, response.Error)
+	var id string
+	assert.Nil(t, json.Unmarshal(response.Result, &id))
+
+	notification, _ := jsonrpc.NewNotification("newHeads", map[string]int{"number": 1})
+	assert.Equal(t, 1, broker.Publish(context.Background(), "newHeads", notification))
+	assert.Contains(t, writer.String(), `"method":"eth_subscription"`)
+	assert.Contains(t, writer.String(), `"subscription":"`+id+`","result":{"number":1}`)
+
+	assert.False(t, broker.HandleRequest(ctx, &jsonrpc.Request{Method: "ping"}, &jsonrpc.Response{}))
+	response = &jsonrpc.Response{}
+	assert.True(t, broker.HandleRequest(ctx, &jsonrpc.Request{Method: "eth_unsubscribe", Params: []byte(`{"subscription":"` + id + `"}`)}, response))
+	assert.JSONEq(t, `true`, string(response.Result))
+	assert.Equal(t, 0, broker.Publish(context.Background(), "newHeads", notification))
+
+	response = &jsonrpc.Response{}
+	broker.HandleRequest(ctx, &jsonrpc.Request{Method: "eth_subscribe", Params: []byte(`[]`)}, response)
+	assert.NotNil(t, response.Error)
+}
+
+func TestBroker_DropOnSessionRemoval(t *testing.T) {
+	handler := NewHandler()
+	broker := NewBroker(handler)
+	newBrokerSession(handler, "s1")
+	broker.Join("s1", "resources")
+	broker.Subscribe("s1", "logs")
+	NewLifecycle(handler, LifecycleOptions{}).Delete("s1")
+	assert.Empty(t, broker.Topics("s1"))
+	broker.mux.RLock()
+	defer broker.mux.RUnlock()
+	assert.Empty(t, broker.topics)
+	assert.Empty(t, broker.subscriptions)
+	assert.Empty(t, broker.subscribers)
+}
+
+func TestBroker_SubscriptionIndex(t *testing.T) {
+	handler := NewHandler()
+	broker := NewBroker(handler)
+	newBrokerSession(handler, "s1")
+	newBrokerSession(handler, "s2")
+	first := broker.Subscribe("s1", "logs")
+	second := broker.Subscribe("s1", "logs")
+	broker.Subscribe("s2", "metrics")
+	notification, _ := jsonrpc.NewNotification("logs", map[string]string{"line": "a"})
+
+	assert.Equal(t, 1, broker.Publish(context.Background(), "logs", notification))
+	assert.True(t, broker.Unsubscribe("s1", first.ID))
+	assert.False(t, broker.Unsubscribe("s2", second.ID), "subscription of another session")
+	assert.Equal(t, 1, broker.Publish(context.Background(), "logs", notification), "remaining subscription still delivers")
+	assert.True(t, broker.Unsubscribe("s1", second.ID))
+	assert.Equal(t, 0, broker.Publish(context.Background(), "logs", notification))
+
+	broker.mux.RLock()
+	defer broker.mux.RUnlock()
+	assert.Equal(t, map[string]map[string]map[string]bool{"metrics": {"s2": {"0x3": true}}}, broker.subscribers)
+	assert.Equal(t, map[string]map[string]bool{"metrics": {"s2": true}}, broker.topics)
+}
@@ -0,0 +1,86 @@
+package base
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventBudget bounds total replay buffer memory across all sessions of a server.
+// When the budget is exceeded, replay buffers of the oldest detached sessions are evicted first,
+// then the oldest events of the session that exceeded the budget.
+type EventBudget struct {
+	limit    int64
+	used     int64
+	mux      sync.Mutex
+	sessions map[*Session]struct{}
+}
+
+// Limit returns budget limit in bytes
+func (b *EventBudget) Limit() int64 {
+	return b.limit
+}
+
+// Used returns bytes currently held by replay buffers
+func (b *EventBudget) Used() int64 {
+	return atomic.LoadInt64(&b.used)
+}
+
+func (b *EventBudget) add(delta int) {
+	if delta != 0 {
+		atomic.AddInt64(&b.used, int64(delta))
+	}
+}
+
+func (b *EventBudget) register(s *Session) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.sessions[s] = struct{}{}
+}
+
+func (b *EventBudget) unregister(s *Session) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	delete(b.sessions, s)
+}
+
+// enforce evicts replay buffers until the budget is met; it must not be called while holding a session mutex.
+func (b *EventBudget) enforce(current *Session) {
+	if b.limit <= 0 || b.Used() <= b.limit {
+		return
+	}
+	type candidate struct {
+		session    *Session
+		detachedAt time.Time
+	}
+	var detached []candidate
+	b.mux.Lock()
+	for aSession := range b.sessions {
+		if aSession == current {
+			continue
+		}
+		aSession.Mutex.Lock()
+		if aSession.State == SessionStateDetached && aSession.DetachedAt != nil {
+			detached = append(detached, candidate{session: aSession, detachedAt: *aSession.DetachedAt})
+		}
+		aSession.Mutex.Unlock()
+	}
+	b.mux.Unlock()
+	sort.Slice(detached, func(i, j int) bool { return detached[i].detachedAt.Before(detached[j].detachedAt) })
+	for _, item := range detached {
+		if b.Used() <= b.limit {
+			return
+		}
+		item.session.evictEvents(false)
+		item.session.notifyOverflow()
+	}
+	if b.Used() > b.limit && current != nil {
+		current.evictEvents(true)
+	}
+}
+
+// NewEventBudget creates a server-wide replay buffer memory budget
+func NewEventBudget(limit int64) *EventBudget {
+	return &EventBudget{limit: limit, sessions: map[*Session]struct{}{}}
+}
@@ -0,0 +1,40 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// chunkWriter returns writer emitting ordered chunk notifications for request id on the request or session stream
+func chunkWriter(session *Session, requestId jsonrpc.RequestId) transport.ChunkWriter {
+	var mux sync.Mutex
+	seq := 0
+	return func(ctx context.Context, data interface{}) error {
+		var raw json.RawMessage
+		switch actual := data.(type) {
+		case json.RawMessage:
+			raw = actual
+		default:
+			var err error
+			if raw, err = json.Marshal(data); err != nil {
+				return err
+			}
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		seq++
+		notification, err := jsonrpc.NewNotification(transport.ChunkNotification, &transport.ChunkParams{
+			RequestId: requestId,
+			Seq:       seq,
+			Data:      raw,
+		})
+		if err != nil {
+			return err
+		}
+		return notifyRequest(ctx, session, notification)
+	}
+}
@@ -0,0 +1,84 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+type chunkHandler struct{}
+
+func (h *chunkHandler) Serve(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	for _, line := range []string{"a", "b", "c"} {
+		if err := transport.WriteChunk(ctx, map[string]string{"line": line}); err != nil {
+			response.Result = []byte(`{"lines":0}`)
+			return
+		}
+	}
+	response.Result = []byte(`{"lines":3}`)
+}
+
+func (h *chunkHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestHandler_Chunks(t *testing.T) {
+	writer := &syncBuffer{}
+	session := NewSession(context.Background(), "s1", writer, func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &chunkHandler{}
+	})
+	NewHandler().HandleMessage(context.Background(), session, []byte(`{"jsonrpc":"2.0","id":7,"method":"logs/tail","params":{"_meta":{"stream":true}}}`), nil)
+
+	var messages []json.RawMessage
+	decoder := json.NewDecoder(strings.NewReader(writer.String()))
+	for decoder.More() {
+		var message json.RawMessage
+		assert.Nil(t, decoder.Decode(&message))
+		messages = append(messages, message)
+	}
+	if !assert.Len(t, messages, 4) {
+		return
+	}
+	for i, message := range messages[:3] {
+		notification := &jsonrpc.Notification{}
+		assert.Nil(t, json.Unmarshal(message, notification))
+		assert.Equal(t, transport.ChunkNotification, notification.Method)
+		chunk := &transport.ChunkParams{}
+		assert.Nil(t, json.Unmarshal(notification.Params, chunk))
+		assert.EqualValues(t, 7, chunk.RequestId)
+		assert.Equal(t, i+1, chunk.Seq)
+	}
+	assert.Contains(t, string(messages[3]), `"result":{"lines":3}`, "final response follows the chunks")
+
+	// request stream receives chunks ahead of the response written to output
+	var streamed [][]byte
+	ctx := WithRequestStream(context.Background(), func(data []byte) error {
+		streamed = append(streamed, data)
+		return nil
+	})
+	output := bytes.Buffer{}
+	NewHandler().HandleMessage(ctx, session, []byte(`{"jsonrpc":"2.0","id":8,"method":"logs/tail","params":{"_meta":{"stream":true}}}`), &output)
+	assert.Len(t, streamed, 3)
+	assert.Contains(t, output.String(), `"id":8`)
+
+	// chunks are not written unless the client requested a stream
+	streamed = nil
+	output.Reset()
+	NewHandler().HandleMessage(ctx, session, []byte(`{"jsonrpc":"2.0","id":9,"method":"logs/tail"}`), &output)
+	assert.Len(t, streamed, 0)
+	assert.Contains(t, output.String(), `"result":{"lines":0}`, "WriteChunk fails without a stream request")
+
+	assert.ErrorIs(t, transport.WriteChunk(context.Background(), "x"), transport.ErrChunksNotSupported)
+}
+
+func TestSetStreamRequested(t *testing.T) {
+	request := &jsonrpc.Request{Params: []byte(`{"name":"x"}`)}
+	assert.False(t, transport.StreamRequested(request))
+	assert.Nil(t, transport.SetStreamRequested(request))
+	assert.True(t, transport.StreamRequested(request))
+	assert.JSONEq(t, `{"name":"x","_meta":{"stream":true}}`, string(request.Params))
+}
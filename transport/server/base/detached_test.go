@@ -0,0 +1,96 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func newDetachedSession(timeout time.Duration) (*Session, transport.Transport) {
+	var tr transport.Transport
+	session := NewSession(context.Background(), "s1", nil, func(ctx context.Context, t transport.Transport) transport.Handler {
+		tr = t
+		return &nopHandler{}
+	}, WithDetachedSendTimeout(timeout))
+	session.MarkDetached()
+	return session, tr
+}
+
+func TestTransport_DetachedSend(t *testing.T) {
+	notification := &jsonrpc.Notification{Jsonrpc: "2.0", Method: "notifications/message"}
+
+	t.Run("held until reattach", func(t *testing.T) {
+		session, tr := newDetachedSession(time.Second)
+		defer session.Release()
+		assert.False(t, tr.(*Transport).Connected())
+
+		done := make(chan error, 1)
+		go func() { done <- tr.Notify(context.Background(), notification) }()
+		time.Sleep(50 * time.Millisecond)
+		writer := &syncBuffer{}
+		session.MarkActiveWithWriter(writer)
+
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("notification was not released on reattach")
+		}
+		assert.Contains(t, writer.String(), "notifications/message")
+		assert.True(t, tr.(*Transport).Connected())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		session, tr := newDetachedSession(50 * time.Millisecond)
+		defer session.Release()
+		_, err := tr.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Id: 1, Method: "sampling/createMessage"})
+		assert.True(t, errors.Is(err, ErrClientNotConnected))
+		var notConnected *NotConnectedError
+		assert.True(t, errors.As(err, &notConnected))
+		assert.Equal(t, "s1", notConnected.SessionID)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		session, tr := newDetachedSession(0)
+		defer session.Release()
+		assert.True(t, IsClientNotConnected(tr.Notify(context.Background(), notification)))
+	})
+
+	t.Run("session closed", func(t *testing.T) {
+		session, tr := newDetachedSession(time.Minute)
+		done := make(chan error, 1)
+		go func() { done <- tr.Notify(context.Background(), notification) }()
+		time.Sleep(20 * time.Millisecond)
+		session.Release()
+		select {
+		case err := <-done:
+			assert.True(t, IsClientNotConnected(err))
+		case <-time.After(time.Second):
+			t.Fatal("notification was not released on close")
+		}
+	})
+}
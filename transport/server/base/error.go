@@ -0,0 +1,31 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrClientNotConnected indicates a server-initiated message could not be delivered
+// because no client stream is attached to the session.
+var ErrClientNotConnected = errors.New("client not connected")
+
+// NotConnectedError represents a failed delivery to a session without an attached client stream.
+type NotConnectedError struct {
+	SessionID string
+	Reason    string
+}
+
+// Error returns error message
+func (e *NotConnectedError) Error() string {
+	return fmt.Sprintf("client not connected: session %v: %v", e.SessionID, e.Reason)
+}
+
+// Is allows errors.Is(err, ErrClientNotConnected)
+func (e *NotConnectedError) Is(target error) bool {
+	return target == ErrClientNotConnected
+}
+
+// IsClientNotConnected returns true if err is or wraps a NotConnectedError.
+func IsClientNotConnected(err error) bool {
+	return errors.Is(err, ErrClientNotConnected)
+}
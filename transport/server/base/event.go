@@ -0,0 +1,127 @@
+package base
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionEventType represents session lifecycle event type
+type SessionEventType string
+
+const (
+	// SessionCreated is published when a new session is registered
+	SessionCreated SessionEventType = "created"
+	// SessionAttached is published when a client stream is attached to the session
+	SessionAttached SessionEventType = "attached"
+	// SessionDetached is published when the client stream is closed
+	SessionDetached SessionEventType = "detached"
+	// SessionReattached is published when a client resumes the stream with Last-Event-ID
+	SessionReattached SessionEventType = "reattached"
+	// SessionOverflowed is published when the replay buffer starts dropping events
+	SessionOverflowed SessionEventType = "overflowed"
+	// SessionExpired is published when the session is removed by lifecycle policy
+	SessionExpired SessionEventType = "expired"
+	// SessionDeleted is published when the client explicitly terminates the session
+	SessionDeleted SessionEventType = "deleted"
+)
+
+// ExpireReason represents why a session expired
+type ExpireReason string
+
+const (
+	ExpireIdle        ExpireReason = "idle"
+	ExpireMaxLifetime ExpireReason = "max_lifetime"
+	ExpireGrace       ExpireReason = "grace"
+	ExpireDisconnect  ExpireReason = "disconnect"
+	ExpireForced      ExpireReason = "forced"
+)
+
+// SessionEvent represents session lifecycle event
+type SessionEvent struct {
+	Type      SessionEventType `json:"type"`
+	SessionID string           `json:"sessionId"`
+	Session   *Session         `json:"-"`
+	Time      time.Time        `json:"time"`
+	// Replayed is the number of events re-delivered on reattach
+	Replayed int `json:"replayed,omitempty"`
+	// Reason is set for expired events
+	Reason ExpireReason `json:"reason,omitempty"`
+}
+
+// NewSessionEvent creates a session event
+func NewSessionEvent(eventType SessionEventType, session *Session) *SessionEvent {
+	ret := &SessionEvent{Type: eventType, Session: session, Time: time.Now()}
+	if session != nil {
+		ret.SessionID = session.Id
+	}
+	return ret
+}
+
+// SessionListener receives session lifecycle events; it is called synchronously and should not block.
+type SessionListener func(event *SessionEvent)
+
+type subscription struct {
+	id       uint64
+	listener SessionListener
+}
+
+// SessionEvents represents session lifecycle event bus
+type SessionEvents struct {
+	mux       sync.RWMutex
+	seq       uint64
+	listeners []subscription
+}
+
+// Subscribe registers listener and returns a function removing it
+func (e *SessionEvents) Subscribe(listener SessionListener) func() {
+	if e == nil || listener == nil {
+		return func() {}
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.seq++
+	id := e.seq
+	e.listeners = append(e.listeners, subscription{id: id, listener: listener})
+	return func() {
+		e.mux.Lock()
+		defer e.mux.Unlock()
+		for i, item := range e.listeners {
+			if item.id == id {
+				e.listeners = append(e.listeners[:i:i], e.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers event to all listeners; a panicking listener does not affect others
+func (e *SessionEvents) Publish(event *SessionEvent) {
+	if e == nil || event == nil {
+		return
+	}
+	e.mux.RLock()
+	listeners := make([]SessionListener, 0, len(e.listeners))
+	for _, item := range e.listeners {
+		listeners = append(listeners, item.listener)
+	}
+	e.mux.RUnlock()
+	for _, listener := range listeners {
+		func() {
+			defer func() { _ = recover() }()
+			listener(event)
+		}()
+	}
+}
+
+// Emit publishes event of the given type for the session
+func (e *SessionEvents) Emit(eventType SessionEventType, session *Session) {
+	if e == nil {
+		return
+	}
+	e.Publish(NewSessionEvent(eventType, session))
+}
+
+// NewSessionEvents creates session lifecycle event bus
+func NewSessionEvents() *SessionEvents {
+	return &SessionEvents{}
+}
@@ -0,0 +1,50 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionEvents(t *testing.T) {
+	events := NewSessionEvents()
+	var received []SessionEventType
+	unsubscribe := events.Subscribe(func(event *SessionEvent) { received = append(received, event.Type) })
+	events.Subscribe(func(event *SessionEvent) { panic("listener failure") })
+
+	session := &Session{Id: "s1"}
+	events.Emit(SessionCreated, session)
+	unsubscribe()
+	events.Emit(SessionDeleted, session)
+	assert.Equal(t, []SessionEventType{SessionCreated}, received)
+}
+
+func TestSession_OverflowEvent(t *testing.T) {
+	events := NewSessionEvents()
+	var overflowed int
+	events.Subscribe(func(event *SessionEvent) {
+		if event.Type == SessionOverflowed {
+			overflowed++
+			// listeners may call back into the session
+			event.Session.BufferedEvents()
+		}
+	})
+	session := newTestSession(&blockingWriter{release: closedChannel()}, WithSSE(), WithEventBuffer(2), WithSessionEvents(events))
+	defer session.Release()
+	for i := 0; i < 5; i++ {
+		session.SendData(context.Background(), []byte(`{"jsonrpc":"2.0","method":"progress"}`))
+	}
+	assert.Equal(t, 1, overflowed)
+	session.MarkActiveWithWriter(&syncBuffer{})
+	for i := 0; i < 3; i++ {
+		session.SendData(context.Background(), []byte(`{"jsonrpc":"2.0","method":"progress"}`))
+	}
+	assert.Equal(t, 2, overflowed)
+}
+
+func closedChannel() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
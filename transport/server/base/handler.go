@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 
 	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
 	"github.com/viant/jsonrpc/transport/base"
 )
 
@@ -15,6 +16,9 @@ import (
 type Handler struct {
 	Sessions SessionStore
 	Logger   jsonrpc.Logger // Logger for error messages
+	Events   *SessionEvents // Events publishes session lifecycle events
+	// Authorizer rejects requests (with a JSON-RPC error) and drops notifications before the session handler runs
+	Authorizer Authorizer
 }
 
 func (e *Handler) HandleMessage(ctx context.Context, session *Session, data []byte, output *bytes.Buffer) {
@@ -32,14 +36,24 @@ func (e *Handler) HandleMessage(ctx context.Context, session *Session, data []by
 		}
 		if request.Id != nil {
 			if intId, ok := jsonrpc.AsRequestIntId(request.Id); ok {
-				nextSeq := uint64(max(intId, int(session.RequestIdSeq)))
+				nextSeq := uint64(max(intId, int(atomic.LoadUint64(&session.RequestIdSeq))))
 				atomic.StoreUint64(&session.RequestIdSeq, nextSeq)
 			}
 		}
 
 		response := &jsonrpc.Response{Id: request.Id, Jsonrpc: request.Jsonrpc}
 		ctx = context.WithValue(ctx, jsonrpc.RequestIdKey, request.Id)
-		session.Handler.Serve(ctx, request, response)
+		if token, ok := transport.ProgressToken(request); ok {
+			ctx = transport.WithProgressReporter(ctx, progressReporter(session, token))
+		}
+		if request.Id != nil && transport.StreamRequested(request) {
+			ctx = transport.WithChunkWriter(ctx, chunkWriter(session, request.Id))
+		}
+		if err := e.authorize(ctx, request.Method); err != nil {
+			response.Error = err
+		} else {
+			session.Handler.Serve(ctx, request, response)
+		}
 		if output != nil {
 			if response.Error != nil {
 				response.Result = nil
@@ -78,13 +92,27 @@ func (e *Handler) HandleMessage(ctx context.Context, session *Session, data []by
 			}
 			return
 		}
+		if err := e.authorize(ctx, notification.Method); err != nil {
+			if e.Logger != nil {
+				e.Logger.Errorf("notification %v rejected: %v", notification.Method, err.Message)
+			}
+			return
+		}
 		session.Handler.OnNotification(ctx, notification)
 	}
 }
 
+func (e *Handler) authorize(ctx context.Context, method string) *jsonrpc.Error {
+	if e.Authorizer == nil {
+		return nil
+	}
+	return e.Authorizer.Authorize(ctx, method)
+}
+
 func NewHandler() *Handler {
 	return &Handler{
 		Sessions: NewMemorySessionStore(),
 		Logger:   jsonrpc.DefaultLogger,
+		Events:   NewSessionEvents(),
 	}
 }
@@ -0,0 +1,311 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// JobFunc runs job work; its result is marshalled as the job result
+type JobFunc func(ctx context.Context) (interface{}, error)
+
+// JobStore persists job state
+type JobStore interface {
+	Put(ctx context.Context, job *transport.Job) error
+	Get(ctx context.Context, id string) (*transport.Job, bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryJobStore is an in-process JobStore
+type MemoryJobStore struct {
+	jobs sync.Map
+}
+
+// Put stores job copy
+func (m *MemoryJobStore) Put(_ context.Context, job *transport.Job) error {
+	clone := *job
+	m.jobs.Store(job.ID, &clone)
+	return nil
+}
+
+// Get returns job copy
+func (m *MemoryJobStore) Get(_ context.Context, id string) (*transport.Job, bool, error) {
+	value, ok := m.jobs.Load(id)
+	if !ok {
+		return nil, false, nil
+	}
+	clone := *value.(*transport.Job)
+	return &clone, true, nil
+}
+
+// Delete removes job
+func (m *MemoryJobStore) Delete(_ context.Context, id string) error {
+	m.jobs.Delete(id)
+	return nil
+}
+
+// NewMemoryJobStore creates in-process job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{}
+}
+
+// Jobs runs long-running requests asynchronously: a handler returns a job handle right away, the client
+// follows the job with job/status, job/result and job/cancel and is notified when the job completes.
+// Running jobs are cancelled when their session expires or is deleted.
+type Jobs struct {
+	handler   *Handler
+	store     JobStore
+	retention time.Duration
+	mux       sync.Mutex
+	cancels   map[string]context.CancelFunc
+	// sessions indexes running job ids by session id
+	sessions map[string]map[string]struct{}
+}
+
+// JobOption represents jobs option
+type JobOption func(j *Jobs)
+
+// WithJobStore sets job store (in-memory by default)
+func WithJobStore(store JobStore) JobOption {
+	return func(j *Jobs) { j.store = store }
+}
+
+// WithJobRetention sets how long finished jobs are kept in the store (15 minutes by default, 0 keeps them)
+func WithJobRetention(retention time.Duration) JobOption {
+	return func(j *Jobs) { j.retention = retention }
+}
+
+// Start runs fn as a job of the session in ctx and returns its handle. The job context is detached from the
+// request context, so the job outlives the request; it is cancelled with job/cancel or when the session is removed.
+func (j *Jobs) Start(ctx context.Context, method string, fn JobFunc) (*transport.JobHandle, error) {
+	aSession, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("session not found")
+	}
+	now := time.Now()
+	job := &transport.Job{
+		ID:        uuid.New().String(),
+		SessionID: aSession.Id,
+		Method:    method,
+		State:     transport.JobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := j.store.Put(ctx, job); err != nil {
+		return nil, err
+	}
+	// progress of a job is reported on the session stream, the request stream closes with the response;
+	// the job result is not chunked as the request is answered with the handle
+	jobCtx := transport.WithChunkWriter(context.WithValue(context.WithoutCancel(ctx), requestStreamContextKey, RequestStream(nil)), nil)
+	jobCtx, cancel := context.WithCancel(jobCtx)
+	j.mux.Lock()
+	j.cancels[job.ID] = cancel
+	if j.sessions[aSession.Id] == nil {
+		j.sessions[aSession.Id] = map[string]struct{}{}
+	}
+	j.sessions[aSession.Id][job.ID] = struct{}{}
+	j.mux.Unlock()
+	handle := &transport.JobHandle{ID: job.ID, State: job.State}
+	go j.run(jobCtx, aSession, job, fn)
+	return handle, nil
+}
+
+// Respond starts fn as a job and sets the job handle as the response result
+func (j *Jobs) Respond(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response, fn JobFunc) {
+	handle, err := j.Start(ctx, request.Method, fn)
+	if err != nil {
+		response.Error = jsonrpc.NewInternalError(err.Error(), nil)
+		return
+	}
+	if response.Result, err = json.Marshal(handle); err != nil {
+		response.Error = jsonrpc.NewInternalError(err.Error(), nil)
+	}
+}
+
+func (j *Jobs) run(ctx context.Context, aSession *Session, job *transport.Job, fn JobFunc) {
+	defer j.release(job)
+	job.State = transport.JobRunning
+	job.UpdatedAt = time.Now()
+	if err := j.update(ctx, job); err != nil {
+		return
+	}
+	result, err := j.call(ctx, fn)
+	switch {
+	case ctx.Err() != nil:
+		job.State = transport.JobCancelled
+		job.Error = jsonrpc.NewInternalError("job cancelled", nil)
+	case err != nil:
+		job.State = transport.JobFailed
+		job.Error = asError(err)
+	default:
+		job.State = transport.JobCompleted
+		if result != nil {
+			if job.Result, err = json.Marshal(result); err != nil {
+				job.State = transport.JobFailed
+				job.Error = jsonrpc.NewInternalError(err.Error(), nil)
+			}
+		}
+	}
+	job.UpdatedAt = time.Now()
+	storeCtx := context.WithoutCancel(ctx)
+	if err = j.store.Put(storeCtx, job); err != nil {
+		if j.handler.Logger != nil {
+			j.handler.Logger.Errorf("failed to store job %v: %v", job.ID, err)
+		}
+		return
+	}
+	if notification, err := jsonrpc.NewNotification(transport.JobCompletedNotification, job); err == nil {
+		_ = aSession.Notify(storeCtx, notification)
+	}
+	if j.retention > 0 {
+		time.AfterFunc(j.retention, func() { _ = j.store.Delete(context.Background(), job.ID) })
+	}
+}
+
+// update stores running job unless it was cancelled before it started
+func (j *Jobs) update(ctx context.Context, job *transport.Job) error {
+	if current, ok, err := j.store.Get(ctx, job.ID); err == nil && ok && current.State.Done() {
+		return fmt.Errorf("job %v is %v", job.ID, current.State)
+	}
+	return j.store.Put(ctx, job)
+}
+
+// call runs fn converting panic into error
+func (j *Jobs) call(ctx context.Context, fn JobFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+func (j *Jobs) release(job *transport.Job) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if cancel, ok := j.cancels[job.ID]; ok {
+		cancel()
+		delete(j.cancels, job.ID)
+	}
+	if ids, ok := j.sessions[job.SessionID]; ok {
+		delete(ids, job.ID)
+		if len(ids) == 0 {
+			delete(j.sessions, job.SessionID)
+		}
+	}
+}
+
+// Get returns job state
+func (j *Jobs) Get(ctx context.Context, id string) (*transport.Job, bool, error) {
+	return j.store.Get(ctx, id)
+}
+
+// Cancel cancels running job; it returns false when the job is unknown or already done
+func (j *Jobs) Cancel(ctx context.Context, id string) bool {
+	job, ok, err := j.store.Get(ctx, id)
+	if err != nil || !ok || job.State.Done() {
+		return false
+	}
+	j.mux.Lock()
+	cancel, running := j.cancels[id]
+	j.mux.Unlock()
+	if !running {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// HandleRequest serves job/status, job/result and job/cancel for jobs of the session in ctx; it returns false
+// for other methods. Params: ["id"] or {"jobId":"..."}. Status returns the job without result, result returns
+// the job with result once done (invalid params error while still running) and cancel returns a boolean.
+func (j *Jobs) HandleRequest(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) bool {
+	switch request.Method {
+	case transport.JobStatusMethod, transport.JobResultMethod, transport.JobCancelMethod:
+	default:
+		return false
+	}
+	aSession, ok := SessionFromContext(ctx)
+	if !ok {
+		response.Error = jsonrpc.NewInternalError("session not found", nil)
+		return true
+	}
+	id, err := firstParam(request.Params, "jobId", "id")
+	if err != nil || id == "" {
+		response.Error = jsonrpc.NewInvalidParamsError(fmt.Sprintf("invalid %v params", request.Method), request.Params)
+		return true
+	}
+	job, ok, err := j.store.Get(ctx, id)
+	if err != nil {
+		response.Error = jsonrpc.NewInternalError(err.Error(), nil)
+		return true
+	}
+	if !ok || job.SessionID != aSession.Id {
+		response.Error = jsonrpc.NewInvalidParamsError(fmt.Sprintf("job %v not found", id), nil)
+		return true
+	}
+	var result interface{}
+	switch request.Method {
+	case transport.JobStatusMethod:
+		job.Result = nil
+		result = job
+	case transport.JobResultMethod:
+		if !job.State.Done() {
+			response.Error = jsonrpc.NewInvalidParamsError(fmt.Sprintf("job %v is %v", id, job.State), nil)
+			return true
+		}
+		result = job
+	case transport.JobCancelMethod:
+		result = j.Cancel(ctx, id)
+	}
+	if response.Result, err = json.Marshal(result); err != nil {
+		response.Error = jsonrpc.NewInternalError(err.Error(), nil)
+	}
+	return true
+}
+
+// onSessionEvent cancels running jobs of removed sessions
+func (j *Jobs) onSessionEvent(event *SessionEvent) {
+	switch event.Type {
+	case SessionExpired, SessionDeleted:
+	default:
+		return
+	}
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	for id := range j.sessions[event.SessionID] {
+		if cancel, ok := j.cancels[id]; ok {
+			cancel()
+		}
+	}
+}
+
+// asError converts error into JSON-RPC error
+func asError(err error) *jsonrpc.Error {
+	if rpcErr, ok := err.(*jsonrpc.Error); ok {
+		return rpcErr
+	}
+	return jsonrpc.NewInternalError(err.Error(), nil)
+}
+
+// NewJobs creates job runner over handler sessions
+func NewJobs(handler *Handler, options ...JobOption) *Jobs {
+	ret := &Jobs{
+		handler:   handler,
+		store:     NewMemoryJobStore(),
+		retention: 15 * time.Minute,
+		cancels:   map[string]context.CancelFunc{},
+		sessions:  map[string]map[string]struct{}{},
+	}
+	for _, option := range options {
+		option(ret)
+	}
+	handler.Events.Subscribe(ret.onSessionEvent)
+	return ret
+}
@@ -0,0 +1,129 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+func callJob(jobs *Jobs, ctx context.Context, method, id string) (*transport.Job, *jsonrpc.Error) {
+	response := &jsonrpc.Response{}
+	jobs.HandleRequest(ctx, &jsonrpc.Request{Method: method, Params: []byte(`{"jobId":"` + id + `"}`)}, response)
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	job := &transport.Job{}
+	_ = json.Unmarshal(response.Result, job)
+	return job, nil
+}
+
+func awaitJobState(jobs *Jobs, id string, state transport.JobState) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok, _ := jobs.Get(context.Background(), id); ok && job.State == state {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestJobs_Lifecycle(t *testing.T) {
+	handler := NewHandler()
+	jobs := NewJobs(handler)
+	aSession, writer := newBrokerSession(handler, "s1")
+	ctx := context.WithValue(context.Background(), jsonrpc.SessionKey, aSession)
+
+	release := make(chan bool)
+	response := &jsonrpc.Response{}
+	jobs.Respond(ctx, &jsonrpc.Request{Method: "index"}, response, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return map[string]int{"files": 3}, nil
+	})
+	assert.Nil(t, response.Error)
+	handle := &transport.JobHandle{}
+	assert.Nil(t, json.Unmarshal(response.Result, handle))
+	assert.Equal(t, transport.JobPending, handle.State)
+	assert.True(t, awaitJobState(jobs, handle.ID, transport.JobRunning))
+
+	_, rpcErr := callJob(jobs, ctx, transport.JobResultMethod, handle.ID)
+	assert.NotNil(t, rpcErr, "result is not available while running")
+
+	close(release)
+	assert.True(t, awaitJobState(jobs, handle.ID, transport.JobCompleted))
+	status, rpcErr := callJob(jobs, ctx, transport.JobStatusMethod, handle.ID)
+	assert.Nil(t, rpcErr)
+	assert.Equal(t, "index", status.Method)
+	assert.Nil(t, status.Result)
+	result, rpcErr := callJob(jobs, ctx, transport.JobResultMethod, handle.ID)
+	assert.Nil(t, rpcErr)
+	assert.JSONEq(t, `{"files":3}`, string(result.Result))
+	assert.Eventually(t, func() bool { return strings.Contains(writer.String(), transport.JobCompletedNotification) }, time.Second, 5*time.Millisecond)
+
+	other, _ := newBrokerSession(handler, "s2")
+	_, rpcErr = callJob(jobs, context.WithValue(context.Background(), jsonrpc.SessionKey, other), transport.JobStatusMethod, handle.ID)
+	assert.NotNil(t, rpcErr, "jobs are scoped to their session")
+	assert.False(t, jobs.HandleRequest(ctx, &jsonrpc.Request{Method: "tools/call"}, &jsonrpc.Response{}))
+}
+
+func TestJobs_Cancel(t *testing.T) {
+	handler := NewHandler()
+	jobs := NewJobs(handler)
+	aSession, _ := newBrokerSession(handler, "s1")
+	ctx := context.WithValue(context.Background(), jsonrpc.SessionKey, aSession)
+
+	started := make(chan bool)
+	handle, err := jobs.Start(ctx, "index", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Nil(t, err)
+	<-started
+	response := &jsonrpc.Response{}
+	jobs.HandleRequest(ctx, &jsonrpc.Request{Method: transport.JobCancelMethod, Params: []byte(`["` + handle.ID + `"]`)}, response)
+	assert.Equal(t, "true", string(response.Result))
+	assert.True(t, awaitJobState(jobs, handle.ID, transport.JobCancelled))
+	assert.False(t, jobs.Cancel(ctx, handle.ID), "finished job cannot be cancelled")
+
+	failed, err := jobs.Start(ctx, "index", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("disk full")
+	})
+	assert.Nil(t, err)
+	assert.True(t, awaitJobState(jobs, failed.ID, transport.JobFailed))
+	job, _, _ := jobs.Get(ctx, failed.ID)
+	assert.Equal(t, "disk full", job.Error.Message)
+}
+
+func TestJobs_SessionDeleted(t *testing.T) {
+	handler := NewHandler()
+	jobs := NewJobs(handler)
+	aSession, _ := newBrokerSession(handler, "s1")
+	ctx := context.WithValue(context.Background(), jsonrpc.SessionKey, aSession)
+	handle, err := jobs.Start(ctx, "index", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Nil(t, err)
+	assert.NotContains(t, handle.ID, aSession.Id, "job id is opaque")
+	// a session whose id extends the deleted one keeps its jobs
+	other, _ := newBrokerSession(handler, "s1-2")
+	otherHandle, err := jobs.Start(context.WithValue(context.Background(), jsonrpc.SessionKey, other), "index", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Nil(t, err)
+	assert.True(t, awaitJobState(jobs, otherHandle.ID, transport.JobRunning))
+	handler.Events.Emit(SessionDeleted, aSession)
+	assert.True(t, awaitJobState(jobs, handle.ID, transport.JobCancelled))
+	job, _, _ := jobs.Get(ctx, otherHandle.ID)
+	assert.Equal(t, transport.JobRunning, job.State, "jobs of other sessions keep running")
+	assert.True(t, jobs.Cancel(ctx, otherHandle.ID))
+}
@@ -1,5 +1,11 @@
 package base
 
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // RemovalPolicy determines when a session should be removed from the session store.
 type RemovalPolicy int
 
@@ -14,3 +20,163 @@ const (
 	// RemovalManual leaves removal entirely to explicit DELETE or external cleanup.
 	RemovalManual
 )
+
+// SessionTTL overrides lifecycle timeouts for a single session; zero values fall back to lifecycle defaults.
+type SessionTTL struct {
+	IdleTTL        time.Duration `json:"idleTTL,omitempty"`
+	MaxLifetime    time.Duration `json:"maxLifetime,omitempty"`
+	ReconnectGrace time.Duration `json:"reconnectGrace,omitempty"`
+}
+
+// ExpiryPolicy decides whether a session should be removed; it returns an empty reason to keep the session.
+type ExpiryPolicy func(session *Session, now time.Time) ExpireReason
+
+// LifecycleOptions represents session lifecycle settings
+type LifecycleOptions struct {
+	ReconnectGrace  time.Duration
+	IdleTTL         time.Duration
+	MaxLifetime     time.Duration
+	CleanupInterval time.Duration
+	RemovalPolicy   RemovalPolicy
+	// Policy replaces the built-in expiry rules when set; use Lifecycle.DefaultPolicy to compose with them.
+	Policy ExpiryPolicy
+	// OnClose hooks run before a session is removed, on every removal path.
+	OnClose []func(*Session)
+}
+
+// Lifecycle expires sessions by policy and removes them from the handler session store,
+// running close hooks and publishing lifecycle events on every removal path.
+type Lifecycle struct {
+	options LifecycleOptions
+	handler *Handler
+	stop    chan struct{}
+	once    sync.Once
+	started int32
+}
+
+// Start starts background sweeper when cleanup interval is positive
+func (l *Lifecycle) Start() {
+	if l.options.CleanupInterval <= 0 || !atomic.CompareAndSwapInt32(&l.started, 0, 1) {
+		return
+	}
+	go l.run()
+}
+
+// Stop stops background sweeper
+func (l *Lifecycle) Stop() {
+	l.once.Do(func() { close(l.stop) })
+}
+
+func (l *Lifecycle) run() {
+	ticker := time.NewTicker(l.options.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case now := <-ticker.C:
+			l.Sweep(now)
+		}
+	}
+}
+
+// Sweep removes all sessions expired at the given time
+func (l *Lifecycle) Sweep(now time.Time) {
+	policy := l.options.Policy
+	if policy == nil {
+		policy = l.DefaultPolicy
+	}
+	expired := map[string]ExpireReason{}
+	l.handler.Sessions.Range(func(id string, session *Session) bool {
+		if reason := policy(session, now); reason != "" {
+			expired[id] = reason
+		}
+		return true
+	})
+	for id, reason := range expired {
+		l.Expire(id, reason)
+	}
+}
+
+// DefaultPolicy applies max lifetime, idle TTL and removal policy, honoring per-session TTL overrides
+func (l *Lifecycle) DefaultPolicy(session *Session, now time.Time) ExpireReason {
+	session.Mutex.Lock()
+	ttl := session.ttl
+	createdAt, lastSeen, state := session.CreatedAt, session.LastSeen, session.State
+	var detachedAt time.Time
+	if session.DetachedAt != nil {
+		detachedAt = *session.DetachedAt
+	}
+	session.Mutex.Unlock()
+
+	maxLifetime := orDefault(ttl.MaxLifetime, l.options.MaxLifetime)
+	if maxLifetime > 0 && now.Sub(createdAt) > maxLifetime {
+		return ExpireMaxLifetime
+	}
+	idleTTL := orDefault(ttl.IdleTTL, l.options.IdleTTL)
+	if idleTTL > 0 && now.Sub(lastSeen) > idleTTL {
+		return ExpireIdle
+	}
+	if state != SessionStateDetached {
+		return ""
+	}
+	switch l.options.RemovalPolicy {
+	case RemovalOnDisconnect:
+		return ExpireDisconnect
+	case RemovalAfterGrace:
+		grace := orDefault(ttl.ReconnectGrace, l.options.ReconnectGrace)
+		if grace > 0 && !detachedAt.IsZero() && now.Sub(detachedAt) > grace {
+			return ExpireGrace
+		}
+	}
+	// RemovalAfterIdle is covered by IdleTTL; RemovalManual leaves removal to explicit DELETE
+	return ""
+}
+
+// Expire removes session with expired event
+func (l *Lifecycle) Expire(id string, reason ExpireReason) bool {
+	return l.remove(id, func(session *Session) *SessionEvent {
+		event := NewSessionEvent(SessionExpired, session)
+		event.Reason = reason
+		return event
+	})
+}
+
+// Delete removes session explicitly terminated by the client
+func (l *Lifecycle) Delete(id string) bool {
+	return l.remove(id, func(session *Session) *SessionEvent {
+		return NewSessionEvent(SessionDeleted, session)
+	})
+}
+
+// remove closes session once: concurrent removals (sweep, DELETE, admin close) race for the session's
+// removal flag and only the winner runs close hooks and publishes the event.
+func (l *Lifecycle) remove(id string, newEvent func(session *Session) *SessionEvent) bool {
+	session, ok := l.handler.Sessions.Get(id)
+	if !ok || !atomic.CompareAndSwapInt32(&session.removing, 0, 1) {
+		return false
+	}
+	for _, hook := range l.options.OnClose {
+		func() {
+			defer func() { _ = recover() }()
+			hook(session)
+		}()
+	}
+	l.handler.Events.Publish(newEvent(session))
+	l.handler.Sessions.Delete(id)
+	// custom stores may not release removed sessions; Release is idempotent and stops the outbound queue writer
+	session.Release()
+	return true
+}
+
+func orDefault(value, defaultValue time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// NewLifecycle creates session lifecycle manager for handler sessions
+func NewLifecycle(handler *Handler, options LifecycleOptions) *Lifecycle {
+	return &Lifecycle{options: options, handler: handler, stop: make(chan struct{})}
+}
@@ -0,0 +1,109 @@
+package base
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle_DefaultPolicy(t *testing.T) {
+	now := time.Now()
+	detachedAt := now.Add(-time.Minute)
+	lifecycle := NewLifecycle(NewHandler(), LifecycleOptions{
+		ReconnectGrace: 30 * time.Second,
+		IdleTTL:        5 * time.Minute,
+		MaxLifetime:    time.Hour,
+		RemovalPolicy:  RemovalAfterGrace,
+	})
+	var testCases = []struct {
+		description string
+		session     *Session
+		expect      ExpireReason
+	}{
+		{description: "active", session: &Session{CreatedAt: now, LastSeen: now}},
+		{description: "max lifetime", session: &Session{CreatedAt: now.Add(-2 * time.Hour), LastSeen: now}, expect: ExpireMaxLifetime},
+		{description: "idle", session: &Session{CreatedAt: now, LastSeen: now.Add(-10 * time.Minute)}, expect: ExpireIdle},
+		{description: "grace", session: &Session{CreatedAt: now, LastSeen: now, State: SessionStateDetached, DetachedAt: &detachedAt}, expect: ExpireGrace},
+		{description: "grace override", session: &Session{CreatedAt: now, LastSeen: now, State: SessionStateDetached, DetachedAt: &detachedAt, ttl: SessionTTL{ReconnectGrace: 2 * time.Minute}}},
+		{description: "idle override", session: &Session{CreatedAt: now, LastSeen: now.Add(-10 * time.Minute), ttl: SessionTTL{IdleTTL: time.Hour}}},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expect, lifecycle.DefaultPolicy(testCase.session, now), testCase.description)
+	}
+}
+
+func TestLifecycle_Removal(t *testing.T) {
+	handler := NewHandler()
+	var closed []string
+	var events []SessionEventType
+	handler.Events.Subscribe(func(event *SessionEvent) { events = append(events, event.Type) })
+	lifecycle := NewLifecycle(handler, LifecycleOptions{
+		Policy: func(session *Session, now time.Time) ExpireReason {
+			if session.Id == "expired" {
+				return "custom"
+			}
+			return ""
+		},
+		OnClose: []func(*Session){func(session *Session) { closed = append(closed, session.Id) }},
+	})
+	for _, id := range []string{"expired", "active", "deleted"} {
+		handler.Sessions.Put(id, &Session{Id: id})
+	}
+
+	lifecycle.Sweep(time.Now())
+	assert.True(t, lifecycle.Delete("deleted"))
+	assert.False(t, lifecycle.Delete("missing"))
+
+	assert.Equal(t, []string{"expired", "deleted"}, closed)
+	assert.Equal(t, []SessionEventType{SessionExpired, SessionDeleted}, events)
+	_, ok := handler.Sessions.Get("active")
+	assert.True(t, ok)
+}
+
+func TestLifecycle_ConcurrentRemoval(t *testing.T) {
+	handler := NewHandler()
+	var closed, published int32
+	handler.Events.Subscribe(func(event *SessionEvent) { atomic.AddInt32(&published, 1) })
+	lifecycle := NewLifecycle(handler, LifecycleOptions{
+		OnClose: []func(*Session){func(session *Session) { atomic.AddInt32(&closed, 1) }},
+	})
+	handler.Sessions.Put("s1", &Session{Id: "s1"})
+
+	var removed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var ok bool
+			if i%2 == 0 {
+				ok = lifecycle.Delete("s1")
+			} else {
+				ok = lifecycle.Expire("s1", ExpireForced)
+			}
+			if ok {
+				atomic.AddInt32(&removed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, removed, "only one removal wins")
+	assert.EqualValues(t, 1, closed, "close hooks run once")
+	assert.EqualValues(t, 1, published, "event published once")
+}
+
+func TestLifecycle_Stop(t *testing.T) {
+	handler := NewHandler()
+	lifecycle := NewLifecycle(handler, LifecycleOptions{CleanupInterval: 10 * time.Millisecond, IdleTTL: time.Millisecond})
+	lifecycle.Start()
+	lifecycle.Stop()
+	lifecycle.Stop()
+	time.Sleep(20 * time.Millisecond)
+	handler.Sessions.Put("s1", &Session{Id: "s1", LastSeen: time.Now().Add(-time.Hour)})
+	time.Sleep(30 * time.Millisecond)
+	_, ok := handler.Sessions.Get("s1")
+	assert.True(t, ok)
+}
@@ -1,5 +1,7 @@
 package base
 
+import "time"
+
 // Option represents option
 type Option func(s *Session)
 
@@ -19,7 +21,38 @@ func WithEventBuffer(size int) Option {
 		defer s.Mutex.Unlock()
 		if size > 0 {
 			s.bufferSize = size
+			s.configureEvents()
+		}
+	}
+}
+
+// WithEventBufferBytes bounds the in-memory event buffer by total size in bytes.
+func WithEventBufferBytes(size int) Option {
+	return func(s *Session) {
+		s.Mutex.Lock()
+		defer s.Mutex.Unlock()
+		if size >= 0 {
+			s.maxEventBytes = size
+			s.configureEvents()
+		}
+	}
+}
+
+// WithEventBudget attaches session event buffer to a server-wide memory budget.
+func WithEventBudget(budget *EventBudget) Option {
+	return func(s *Session) {
+		if budget == nil {
+			return
+		}
+		s.Mutex.Lock()
+		if s.budget == budget {
+			s.Mutex.Unlock()
+			return
 		}
+		s.budget = budget
+		budget.add(s.events.bytes)
+		s.Mutex.Unlock()
+		budget.register(s)
 	}
 }
 
@@ -51,3 +84,45 @@ func WithEventOverflowPolicy(policy OverflowPolicy) Option {
 		s.overflowPolicy = policy
 	}
 }
+
+// WithOutboundQueue enables asynchronous outbound queue with a dedicated writer goroutine,
+// so that slow clients do not block goroutines sending to the session.
+func WithOutboundQueue(options QueueOptions) Option {
+	return func(s *Session) {
+		s.Mutex.Lock()
+		defer s.Mutex.Unlock()
+		if s.queue != nil {
+			return
+		}
+		s.queue = newOutboundQueue(options)
+		go s.queue.run(s.writeOut)
+	}
+}
+
+// WithDetachedSendTimeout sets how long server-initiated requests and notifications are held
+// while no client stream is attached before failing with NotConnectedError.
+func WithDetachedSendTimeout(timeout time.Duration) Option {
+	return func(s *Session) {
+		s.Mutex.Lock()
+		defer s.Mutex.Unlock()
+		s.detachedSendTimeout = timeout
+	}
+}
+
+// WithTripTimeout sets how long server-initiated requests wait for the client response.
+func WithTripTimeout(timeout time.Duration) Option {
+	return func(s *Session) {
+		s.Mutex.Lock()
+		defer s.Mutex.Unlock()
+		s.tripTimeout = timeout
+	}
+}
+
+// WithSessionEvents sets lifecycle event bus used to publish session events (e.g. replay buffer overflow).
+func WithSessionEvents(events *SessionEvents) Option {
+	return func(s *Session) {
+		s.Mutex.Lock()
+		defer s.Mutex.Unlock()
+		s.lifecycle = events
+	}
+}
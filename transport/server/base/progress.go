@@ -0,0 +1,50 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// RequestStream writes a message on the stream dedicated to the request being served
+// (e.g. a streamable POST upgraded to SSE) instead of the session stream.
+type RequestStream func(data []byte) error
+
+type requestStreamKey string
+
+const requestStreamContextKey = requestStreamKey("jsonrpc-request-stream")
+
+// WithRequestStream returns context routing request scoped messages (e.g. progress) to stream
+func WithRequestStream(ctx context.Context, stream RequestStream) context.Context {
+	return context.WithValue(ctx, requestStreamContextKey, stream)
+}
+
+// progressReporter returns reporter emitting progress notifications for token on the request or session stream
+func progressReporter(session *Session, token any) transport.ProgressReporter {
+	return func(ctx context.Context, done, total float64, message string) error {
+		notification, err := jsonrpc.NewNotification(transport.ProgressNotification, &transport.ProgressParams{
+			ProgressToken: token,
+			Progress:      done,
+			Total:         total,
+			Message:       message,
+		})
+		if err != nil {
+			return err
+		}
+		return notifyRequest(ctx, session, notification)
+	}
+}
+
+// notifyRequest sends request scoped notification on the request stream when present, otherwise on the session stream
+func notifyRequest(ctx context.Context, session *Session, notification *jsonrpc.Notification) error {
+	if stream, ok := ctx.Value(requestStreamContextKey).(RequestStream); ok && stream != nil {
+		data, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+		return stream(data)
+	}
+	return session.Notify(ctx, notification)
+}
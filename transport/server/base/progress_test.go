@@ -0,0 +1,55 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+type progressHandler struct{}
+
+func (h *progressHandler) Serve(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	_ = transport.Progress(ctx, 1, 2, "half")
+	response.Result = []byte(`{}`)
+}
+
+func (h *progressHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestHandler_Progress(t *testing.T) {
+	var testCases = []struct {
+		description string
+		params      string
+		expect      string
+	}{
+		{description: "meta token", params: `{"_meta":{"progressToken":"abc"}}`, expect: `"progressToken":"abc","progress":1,"total":2,"message":"half"`},
+		{description: "params token", params: `{"progressToken":7}`, expect: `"progressToken":7`},
+		{description: "no token", params: `{}`},
+	}
+	for _, testCase := range testCases {
+		writer := &syncBuffer{}
+		session := NewSession(context.Background(), "s1", writer, func(ctx context.Context, tr transport.Transport) transport.Handler {
+			return &progressHandler{}
+		})
+		output := bytes.Buffer{}
+		NewHandler().HandleMessage(context.Background(), session, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":`+testCase.params+`}`), &output)
+		if testCase.expect == "" {
+			assert.Empty(t, writer.String(), testCase.description)
+			continue
+		}
+		assert.Contains(t, writer.String(), transport.ProgressNotification, testCase.description)
+		assert.Contains(t, writer.String(), testCase.expect, testCase.description)
+	}
+}
+
+func TestSetProgressToken(t *testing.T) {
+	request := &jsonrpc.Request{Params: []byte(`{"name":"x","_meta":{"traceId":"t"}}`)}
+	assert.Nil(t, transport.SetProgressToken(request, 5))
+	token, ok := transport.ProgressToken(request)
+	assert.True(t, ok)
+	assert.EqualValues(t, 5, token)
+	assert.JSONEq(t, `{"name":"x","_meta":{"traceId":"t","progressToken":5}}`, string(request.Params))
+}
@@ -0,0 +1,243 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// QueuePolicy defines how the outbound queue behaves when it is full.
+type QueuePolicy int
+
+const (
+	// QueueBlock blocks the sender until space is available (or sender context is done).
+	QueueBlock QueuePolicy = iota
+	// QueueDropNotifications drops notifications when full; requests and responses still block.
+	QueueDropNotifications
+	// QueueDisconnect disconnects the slow client stream when full.
+	QueueDisconnect
+)
+
+var (
+	// ErrQueueFull indicates a message was dropped because the outbound queue was full.
+	ErrQueueFull = errors.New("outbound queue is full")
+	// ErrQueueClosed indicates the outbound queue was closed.
+	ErrQueueClosed = errors.New("outbound queue is closed")
+	// errNoStream indicates a dequeued message was not written because no client stream was attached.
+	errNoStream = errors.New("no client stream attached")
+)
+
+// QueueOptions configures asynchronous per-session outbound queue.
+type QueueOptions struct {
+	// Depth is the max number of pending messages (default 256).
+	Depth int
+	// MaxBytes is the max total size of pending messages; zero means unlimited.
+	MaxBytes int
+	// Policy defines behaviour when the queue is full.
+	Policy QueuePolicy
+}
+
+// QueueMetrics represents outbound queue counters; Written counts messages written to a client stream,
+// Detached counts messages dequeued while no stream was attached (kept for replay when buffering is enabled).
+type QueueMetrics struct {
+	Enqueued    uint64 `json:"enqueued"`
+	Written     uint64 `json:"written"`
+	Detached    uint64 `json:"detached"`
+	Dropped     uint64 `json:"dropped"`
+	Disconnects uint64 `json:"disconnects"`
+	WriteErrors uint64 `json:"writeErrors"`
+	Depth       int    `json:"depth"`
+	Bytes       int    `json:"bytes"`
+	HighWater   int    `json:"highWater"`
+}
+
+type outboundItem struct {
+	data []byte
+}
+
+// waitingItem is a message waiting for queue space; admitted is closed once it is moved to the queue.
+type waitingItem struct {
+	data     []byte
+	admitted chan struct{}
+}
+
+// outboundQueue decouples session senders from a slow writer.
+type outboundQueue struct {
+	options   QueueOptions
+	mux       sync.Mutex
+	items     []outboundItem
+	waiting   []*waitingItem
+	bytes     int
+	highWater int
+	ready     chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	enqueued    uint64
+	written     uint64
+	detached    uint64
+	dropped     uint64
+	disconnects uint64
+	writeErrors uint64
+}
+
+func (q *outboundQueue) fits(size int) bool {
+	if len(q.items) == 0 {
+		return true
+	}
+	if len(q.items) >= q.options.Depth {
+		return false
+	}
+	return q.options.MaxBytes <= 0 || q.bytes+size <= q.options.MaxBytes
+}
+
+// offer adds data to the queue without blocking, applying the full policy. When data has to wait for space
+// it joins the waiting line, which keeps offer order, and the returned item must be passed to await.
+// It returns ErrQueueFull when message was dropped and the client should be disconnected under QueueDisconnect policy.
+func (q *outboundQueue) offer(data []byte, notification bool) (*waitingItem, error) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	select {
+	case <-q.closed:
+		return nil, ErrQueueClosed
+	default:
+	}
+	if len(q.waiting) == 0 && q.fits(len(data)) {
+		q.push(data)
+		select {
+		case q.ready <- struct{}{}:
+		default:
+		}
+		return nil, nil
+	}
+	switch q.options.Policy {
+	case QueueDisconnect:
+		atomic.AddUint64(&q.dropped, 1)
+		atomic.AddUint64(&q.disconnects, 1)
+		return nil, ErrQueueFull
+	case QueueDropNotifications:
+		if notification {
+			atomic.AddUint64(&q.dropped, 1)
+			return nil, ErrQueueFull
+		}
+	}
+	waiting := &waitingItem{data: data, admitted: make(chan struct{})}
+	q.waiting = append(q.waiting, waiting)
+	return waiting, nil
+}
+
+// await waits until waiting item is admitted to the queue, the queue is closed or ctx is done.
+func (q *outboundQueue) await(ctx context.Context, waiting *waitingItem) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-waiting.admitted:
+		return nil
+	case <-q.closed:
+		return ErrQueueClosed
+	case <-ctx.Done():
+	}
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	for i, candidate := range q.waiting {
+		if candidate == waiting {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			atomic.AddUint64(&q.dropped, 1)
+			return ctx.Err()
+		}
+	}
+	return nil // admitted while ctx was being cancelled
+}
+
+// push appends data to the queue; caller must hold the mutex.
+func (q *outboundQueue) push(data []byte) {
+	q.items = append(q.items, outboundItem{data: data})
+	q.bytes += len(data)
+	if len(q.items) > q.highWater {
+		q.highWater = len(q.items)
+	}
+	atomic.AddUint64(&q.enqueued, 1)
+}
+
+// admit moves waiting messages that now fit into the queue, in order; caller must hold the mutex.
+func (q *outboundQueue) admit() {
+	for len(q.waiting) > 0 && q.fits(len(q.waiting[0].data)) {
+		waiting := q.waiting[0]
+		q.waiting[0] = nil
+		q.waiting = q.waiting[1:]
+		q.push(waiting.data)
+		close(waiting.admitted)
+	}
+}
+
+// run writes queued messages until the queue is closed (session release).
+func (q *outboundQueue) run(write func(data []byte) error) {
+	for {
+		select {
+		case <-q.closed:
+			return
+		case <-q.ready:
+		}
+		for {
+			q.mux.Lock()
+			if len(q.items) == 0 {
+				q.mux.Unlock()
+				break
+			}
+			item := q.items[0]
+			q.items[0] = outboundItem{}
+			q.items = q.items[1:]
+			q.mux.Unlock()
+
+			switch err := write(item.data); {
+			case err == nil:
+				atomic.AddUint64(&q.written, 1)
+			case errors.Is(err, errNoStream):
+				atomic.AddUint64(&q.detached, 1)
+			default:
+				atomic.AddUint64(&q.writeErrors, 1)
+			}
+
+			q.mux.Lock()
+			q.bytes -= len(item.data)
+			q.admit()
+			q.mux.Unlock()
+		}
+	}
+}
+
+func (q *outboundQueue) close() {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+}
+
+func (q *outboundQueue) metrics() QueueMetrics {
+	q.mux.Lock()
+	depth, size, highWater := len(q.items), q.bytes, q.highWater
+	q.mux.Unlock()
+	return QueueMetrics{
+		Enqueued:    atomic.LoadUint64(&q.enqueued),
+		Written:     atomic.LoadUint64(&q.written),
+		Detached:    atomic.LoadUint64(&q.detached),
+		Dropped:     atomic.LoadUint64(&q.dropped),
+		Disconnects: atomic.LoadUint64(&q.disconnects),
+		WriteErrors: atomic.LoadUint64(&q.writeErrors),
+		Depth:       depth,
+		Bytes:       size,
+		HighWater:   highWater,
+	}
+}
+
+func newOutboundQueue(options QueueOptions) *outboundQueue {
+	if options.Depth <= 0 {
+		options.Depth = 256
+	}
+	return &outboundQueue{
+		options: options,
+		ready:   make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+}
@@ -0,0 +1,218 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// blockingWriter blocks every write until released.
+type blockingWriter struct {
+	mux     sync.Mutex
+	release chan struct{}
+	writes  [][]byte
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (w *blockingWriter) count() int {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return len(w.writes)
+}
+
+type nopHandler struct{}
+
+func (h *nopHandler) Serve(_ context.Context, _ *jsonrpc.Request, _ *jsonrpc.Response) {}
+func (h *nopHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification)        {}
+
+func newTestSession(writer *blockingWriter, options ...Option) *Session {
+	return NewSession(context.Background(), "s1", writer, func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &nopHandler{}
+	}, options...)
+}
+
+func TestSession_OutboundQueue(t *testing.T) {
+	notification := []byte(`{"jsonrpc":"2.0","method":"progress"}`)
+	request := []byte(`{"jsonrpc":"2.0","id":1,"method":"sampling"}`)
+
+	t.Run("slow writer does not block senders", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 8}))
+		defer session.Release()
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 5; i++ {
+				session.SendData(context.Background(), notification)
+			}
+			session.Touch()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("SendData blocked on slow writer")
+		}
+		close(writer.release)
+		assert.Eventually(t, func() bool { return writer.count() == 5 }, time.Second, 5*time.Millisecond)
+		metrics := session.QueueMetrics()
+		assert.EqualValues(t, 5, metrics.Enqueued)
+		assert.EqualValues(t, 5, metrics.Written)
+	})
+
+	t.Run("drop notifications when full", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 2, Policy: QueueDropNotifications}))
+		defer session.Release()
+		// wait until writer picks up the first message and blocks on it
+		session.SendData(context.Background(), notification)
+		assert.Eventually(t, func() bool { return session.QueueMetrics().Depth == 0 }, time.Second, time.Millisecond)
+		for i := 0; i < 5; i++ {
+			session.SendData(context.Background(), notification)
+		}
+		metrics := session.QueueMetrics()
+		assert.True(t, metrics.Dropped >= 3, "dropped: %v", metrics.Dropped)
+
+		sent := make(chan struct{})
+		go func() {
+			session.SendData(context.Background(), request)
+			close(sent)
+		}()
+		select {
+		case <-sent:
+			t.Fatalf("request should block rather than being dropped")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(writer.release)
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatalf("request was not enqueued after queue drained")
+		}
+	})
+
+	t.Run("disconnect when full", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		defer close(writer.release)
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 1, MaxBytes: 1024, Policy: QueueDisconnect}))
+		defer session.Release()
+		streamDone := session.StreamDone()
+		for i := 0; i < 4; i++ {
+			session.SendData(context.Background(), request)
+		}
+		select {
+		case <-streamDone:
+		case <-time.After(time.Second):
+			t.Fatalf("expected stream to be disconnected")
+		}
+		assert.True(t, session.QueueMetrics().Disconnects > 0)
+	})
+
+	t.Run("blocked request does not hold up dropped notifications", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 1, Policy: QueueDropNotifications}))
+		defer session.Release()
+		session.SendData(context.Background(), notification)
+		assert.Eventually(t, func() bool { return session.QueueMetrics().Depth == 0 }, time.Second, time.Millisecond)
+		session.SendData(context.Background(), notification)
+
+		sent := make(chan struct{})
+		go func() {
+			session.SendData(context.Background(), request)
+			close(sent)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		dropped := make(chan struct{})
+		go func() {
+			for i := 0; i < 3; i++ {
+				session.SendData(context.Background(), notification)
+			}
+			close(dropped)
+		}()
+		select {
+		case <-dropped:
+		case <-time.After(time.Second):
+			t.Fatalf("notifications blocked behind a waiting request")
+		}
+		assert.True(t, session.QueueMetrics().Dropped >= 3, "dropped: %v", session.QueueMetrics().Dropped)
+		close(writer.release)
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatalf("request was not enqueued after queue drained")
+		}
+		assert.Eventually(t, func() bool { return writer.count() == 3 }, time.Second, 5*time.Millisecond, "request written after earlier notifications")
+	})
+
+	t.Run("blocked sender honours context", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		defer close(writer.release)
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 1}))
+		defer session.Release()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		for i := 0; i < 4; i++ {
+			session.SendData(ctx, request)
+		}
+		assert.True(t, session.QueueMetrics().Dropped > 0)
+	})
+
+	t.Run("detached drops are not counted as written", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		close(writer.release)
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 8}))
+		defer session.Release()
+		session.MarkDetached()
+		for i := 0; i < 3; i++ {
+			session.SendData(context.Background(), notification)
+		}
+		assert.Eventually(t, func() bool { return session.QueueMetrics().Detached == 3 }, time.Second, 5*time.Millisecond)
+		assert.EqualValues(t, 0, session.QueueMetrics().Written)
+		assert.Equal(t, 0, writer.count())
+	})
+
+	t.Run("writer stops when session expires", func(t *testing.T) {
+		writer := &blockingWriter{release: make(chan struct{})}
+		close(writer.release)
+		handler := NewHandler()
+		handler.Sessions = &nonReleasingStore{}
+		session := newTestSession(writer, WithOutboundQueue(QueueOptions{Depth: 8}))
+		handler.Sessions.Put(session.Id, session)
+		NewLifecycle(handler, LifecycleOptions{}).Expire(session.Id, ExpireIdle)
+		select {
+		case <-session.queue.closed:
+		case <-time.After(time.Second):
+			t.Fatalf("outbound queue writer still running after session expired")
+		}
+	})
+}
+
+// nonReleasingStore is a custom store that does not release deleted sessions
+type nonReleasingStore struct {
+	sessions sync.Map
+}
+
+func (s *nonReleasingStore) Put(id string, aSession *Session) { s.sessions.Store(id, aSession) }
+func (s *nonReleasingStore) Delete(id string)                 { s.sessions.Delete(id) }
+func (s *nonReleasingStore) Get(id string) (*Session, bool) {
+	value, ok := s.sessions.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return value.(*Session), true
+}
+func (s *nonReleasingStore) Range(f func(id string, aSession *Session) bool) {
+	s.sessions.Range(func(key, value any) bool { return f(key.(string), value.(*Session)) })
+}
@@ -0,0 +1,140 @@
+package base
+
+import (
+	"sort"
+
+	"github.com/viant/jsonrpc/transport"
+)
+
+// eventRing is a circular replay buffer bounded by event count and total bytes.
+// Event ids are monotonically increasing, so lookup by id is a binary search.
+type eventRing struct {
+	buf      []event
+	head     int
+	size     int
+	bytes    int
+	maxCount int
+	maxBytes int
+	// evicted is the highest event id dropped from the buffer
+	evicted uint64
+}
+
+// at returns i-th oldest event
+func (r *eventRing) at(i int) *event {
+	return &r.buf[(r.head+i)%len(r.buf)]
+}
+
+// configure sets ring limits, evicting oldest events that no longer fit; returns evicted count and bytes.
+func (r *eventRing) configure(maxCount, maxBytes int) (int, int) {
+	r.maxCount = maxCount
+	r.maxBytes = maxBytes
+	count, size := r.trim(0, false)
+	if len(r.buf) > maxCount {
+		r.grow(maxCount)
+	}
+	return count, size
+}
+
+// push appends event, evicting oldest ones to stay within limits; returns evicted count and bytes.
+// An event larger than the byte limit is not retained and is reported as evicted.
+func (r *eventRing) push(ev event) (int, int) {
+	if r.maxCount <= 0 {
+		return 0, 0
+	}
+	if r.maxBytes > 0 && len(ev.data) > r.maxBytes {
+		count, size := r.clear()
+		r.evicted = ev.id
+		return count + 1, size
+	}
+	count, size := r.trim(len(ev.data), true)
+	if r.size == len(r.buf) {
+		r.grow(min(max(2*len(r.buf), 16), r.maxCount))
+	}
+	r.buf[(r.head+r.size)%len(r.buf)] = ev
+	r.size++
+	r.bytes += len(ev.data)
+	return count, size
+}
+
+// trim evicts oldest events so that an incoming event of the given size fits
+func (r *eventRing) trim(incoming int, pushing bool) (int, int) {
+	limit := r.maxCount
+	if pushing {
+		limit--
+	}
+	count, size := 0, 0
+	for r.size > 0 && (r.size > limit || r.maxBytes > 0 && r.bytes+incoming > r.maxBytes) {
+		size += r.dropOldest()
+		count++
+	}
+	return count, size
+}
+
+// dropOldest removes the oldest event and returns its size
+func (r *eventRing) dropOldest() int {
+	if r.size == 0 {
+		return 0
+	}
+	oldest := r.at(0)
+	size := len(oldest.data)
+	r.evicted = oldest.id
+	*oldest = event{}
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	r.bytes -= size
+	return size
+}
+
+// grow reallocates buffer preserving order of retained events
+func (r *eventRing) grow(capacity int) {
+	if capacity < r.size {
+		capacity = r.size
+	}
+	buf := make([]event, capacity)
+	for i := 0; i < r.size; i++ {
+		buf[i] = *r.at(i)
+	}
+	r.buf = buf
+	r.head = 0
+}
+
+// clear removes all events and returns released count and bytes
+func (r *eventRing) clear() (int, int) {
+	count, size := r.size, r.bytes
+	if r.size > 0 {
+		r.evicted = r.at(r.size - 1).id
+	}
+	r.buf = nil
+	r.head, r.size, r.bytes = 0, 0, 0
+	return count, size
+}
+
+// after returns events with id greater than lastID
+func (r *eventRing) after(lastID uint64) [][]byte {
+	idx := sort.Search(r.size, func(i int) bool { return r.at(i).id > lastID })
+	if idx >= r.size {
+		return nil
+	}
+	result := make([][]byte, r.size-idx)
+	for i := idx; i < r.size; i++ {
+		result[i-idx] = r.at(i).data
+	}
+	return result
+}
+
+// len returns number of buffered events
+func (r *eventRing) len() int {
+	return r.size
+}
+
+// gap returns replay gap for lastID when events following it were evicted
+func (r *eventRing) gap(lastID uint64) *transport.ReplayGap {
+	if lastID >= r.evicted {
+		return nil
+	}
+	oldest := r.evicted + 1
+	if r.size > 0 {
+		oldest = r.at(0).id
+	}
+	return &transport.ReplayGap{LastEventID: lastID, OldestAvailableID: oldest}
+}
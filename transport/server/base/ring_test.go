@@ -0,0 +1,120 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRing(t *testing.T) {
+	payload := func(id int) []byte { return []byte(fmt.Sprintf("event-%03d", id)) } // 9 bytes each
+
+	testCases := []struct {
+		name      string
+		maxCount  int
+		maxBytes  int
+		push      int
+		lastID    uint64
+		expect    []string
+		expectLen int
+	}{
+		{name: "within limits", maxCount: 8, push: 3, lastID: 0, expect: []string{"event-001", "event-002", "event-003"}, expectLen: 3},
+		{name: "count bound", maxCount: 3, push: 5, lastID: 0, expect: []string{"event-003", "event-004", "event-005"}, expectLen: 3},
+		{name: "byte bound", maxCount: 100, maxBytes: 20, push: 5, lastID: 0, expect: []string{"event-004", "event-005"}, expectLen: 2},
+		{name: "after id", maxCount: 100, push: 40, lastID: 37, expect: []string{"event-038", "event-039", "event-040"}, expectLen: 40},
+		{name: "after last", maxCount: 100, push: 4, lastID: 4, expect: nil, expectLen: 4},
+		{name: "wrapped ring", maxCount: 16, push: 35, lastID: 33, expect: []string{"event-034", "event-035"}, expectLen: 16},
+	}
+	for _, tc := range testCases {
+		ring := &eventRing{}
+		ring.configure(tc.maxCount, tc.maxBytes)
+		for i := 1; i <= tc.push; i++ {
+			ring.push(event{id: uint64(i), data: payload(i)})
+		}
+		var actual []string
+		for _, data := range ring.after(tc.lastID) {
+			actual = append(actual, string(data))
+		}
+		assert.EqualValues(t, tc.expect, actual, tc.name)
+		assert.Equal(t, tc.expectLen, ring.len(), tc.name)
+		if tc.maxBytes > 0 {
+			assert.True(t, ring.bytes <= tc.maxBytes, tc.name)
+		}
+	}
+}
+
+func TestEventRing_Configure(t *testing.T) {
+	ring := &eventRing{}
+	ring.configure(10, 0)
+	for i := 1; i <= 10; i++ {
+		ring.push(event{id: uint64(i), data: []byte("x")})
+	}
+	evicted, size := ring.configure(4, 0)
+	assert.Equal(t, 6, evicted)
+	assert.Equal(t, 6, size)
+	assert.Equal(t, 4, len(ring.after(0)))
+	evicted, _ = ring.push(event{id: 11, data: []byte("oversized")})
+	assert.Equal(t, 1, evicted)
+	assert.Equal(t, 4, ring.len())
+}
+
+func TestEventBudget(t *testing.T) {
+	budget := NewEventBudget(100)
+	newSession := func(id string) *Session {
+		aSession := &Session{Id: id}
+		WithEventBuffer(100)(aSession)
+		WithEventBudget(budget)(aSession)
+		return aSession
+	}
+	detached := newSession("detached")
+	active := newSession("active")
+	data := make([]byte, 30)
+
+	for i := 0; i < 3; i++ {
+		detached.SendData(context.Background(), data)
+	}
+	detached.MarkDetached()
+	assert.EqualValues(t, 90, budget.Used())
+
+	for i := 0; i < 2; i++ {
+		active.SendData(context.Background(), data)
+	}
+	// detached session buffer is evicted first
+	count, _ := detached.BufferedEvents()
+	assert.Equal(t, 0, count)
+	count, _ = active.BufferedEvents()
+	assert.Equal(t, 2, count)
+	assert.True(t, budget.Used() <= budget.Limit())
+
+	for i := 0; i < 5; i++ {
+		active.SendData(context.Background(), data)
+	}
+	count, _ = active.BufferedEvents()
+	assert.Equal(t, 3, count)
+	assert.True(t, budget.Used() <= budget.Limit())
+
+	active.Release()
+	detached.Release()
+	assert.EqualValues(t, 0, budget.Used())
+}
+
+func TestEventRing_Gap(t *testing.T) {
+	ring := &eventRing{}
+	ring.configure(3, 0)
+	for i := 1; i <= 3; i++ {
+		ring.push(event{id: uint64(i), data: []byte("x")})
+	}
+	assert.Nil(t, ring.gap(0))
+	for i := 4; i <= 6; i++ {
+		ring.push(event{id: uint64(i), data: []byte("x")})
+	}
+	assert.Nil(t, ring.gap(3))
+	assert.Nil(t, ring.gap(5))
+	gap := ring.gap(1)
+	if assert.NotNil(t, gap) {
+		assert.EqualValues(t, 1, gap.LastEventID)
+		assert.EqualValues(t, 4, gap.OldestAvailableID)
+	}
+}
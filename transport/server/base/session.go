@@ -3,10 +3,12 @@ package base
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/base"
 	"io"
 	"sync"
 	"sync/atomic"
@@ -21,7 +23,7 @@ type Session struct {
 	framer       FrameMessage
 	RequestIdSeq uint64
 	bufferSize   int
-	events       []event
+	events       eventRing
 	err          error
 	closed       int32
 	sync.Mutex
@@ -38,9 +40,41 @@ type Session struct {
 	// buffer overflow handling
 	overflowPolicy OverflowPolicy
 	overflowed     bool
+	// maxEventBytes bounds replay buffer size in bytes (zero means unbounded)
+	maxEventBytes int
+	// budget bounds replay buffer memory across sessions
+	budget *EventBudget
 
 	// writerGen increments on each writer (re)attachment to guard concurrent writers.
 	writerGen uint64
+
+	// Attributes holds application state bound to the session lifetime.
+	Attributes Attributes `json:"attributes"`
+
+	// queue is an optional asynchronous outbound queue; sendMu keeps enqueue order aligned with event ids.
+	queue  *outboundQueue
+	sendMu sync.Mutex
+	// writeMu serializes writes to the attached writer.
+	writeMu sync.Mutex
+	// streamDone is closed when the current client stream is detached or should be disconnected.
+	streamDone chan struct{}
+	// attached is closed when a client stream gets attached; released is closed when session is released.
+	attached chan struct{}
+	// resuming is set between AttachWriter and Resume while missed events are replayed.
+	resuming bool
+	released chan struct{}
+	// detachedSendTimeout bounds how long server-initiated messages are held while no stream is attached.
+	detachedSendTimeout time.Duration
+	// lifecycle publishes session events; overflowPending defers overflow event until the mutex is released
+	lifecycle        *SessionEvents
+	overflowPending  bool
+	overflowNotified bool
+	// ttl overrides lifecycle timeouts for this session
+	ttl SessionTTL
+	// tripTimeout bounds server-initiated round trips (transport default when zero)
+	tripTimeout time.Duration
+	// removing is set once by the lifecycle removal that owns closing this session
+	removing int32
 }
 
 // LastRequestID returns the most recently generated request id without mutating the underlying sequence.
@@ -108,6 +142,19 @@ func (s *Session) SendRequest(ctx context.Context, request *jsonrpc.Request) {
 
 }
 
+// Notify sends notification to the client
+func (s *Session) Notify(ctx context.Context, notification *jsonrpc.Notification) error {
+	if notification.Jsonrpc == "" {
+		notification.Jsonrpc = jsonrpc.Version
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	s.SendData(ctx, data)
+	return nil
+}
+
 func (s *Session) sendNotification(ctx context.Context, notification *jsonrpc.Notification) error {
 	params, err := json.Marshal(notification)
 	if err != nil {
@@ -128,76 +175,190 @@ func (s *Session) sendNotification(ctx context.Context, notification *jsonrpc.No
 
 // SendData sends data
 func (s *Session) SendData(ctx context.Context, data []byte) {
+	if s.queue != nil {
+		s.enqueueData(ctx, data)
+		return
+	}
 	s.Mutex.Lock()
-	defer s.Mutex.Unlock()
+	framed := s.prepareData(data)
+	if s.Writer != nil {
+		s.writeMu.Lock()
+		_, err := s.Writer.Write(framed)
+		s.writeMu.Unlock()
+		if err != nil {
+			s.SetError(err)
+		}
+	}
+	s.Mutex.Unlock()
+	s.enforceBudget()
+}
+
+// enqueueData frames and buffers data, then hands it off to the outbound queue writer.
+// sendMu covers framing and the queue offer only, so a sender waiting for space does not hold up
+// notifications that the queue policy drops.
+func (s *Session) enqueueData(ctx context.Context, data []byte) {
+	notification := base.MessageType(data) == jsonrpc.MessageTypeNotification
+	s.sendMu.Lock()
+	s.Mutex.Lock()
+	framed := s.prepareData(data)
+	s.Mutex.Unlock()
+	waiting, err := s.queue.offer(framed, notification)
+	s.sendMu.Unlock()
+	s.enforceBudget()
+	if waiting != nil {
+		err = s.queue.await(ctx, waiting)
+	}
+	if errors.Is(err, ErrQueueFull) && s.queue.options.Policy == QueueDisconnect {
+		s.Disconnect()
+	}
+}
+
+// prepareData frames data and stores it for replay; caller must hold the session mutex.
+func (s *Session) prepareData(data []byte) []byte {
 	s.LastSeen = time.Now()
 	framed := s.frameMessage(data)
 	if s.sse {
 		id := atomic.AddUint64(&s.RequestIdSeq, 1)
 		prefix := []byte(fmt.Sprintf("id: %d\n", id))
 		full := append(prefix, framed...)
-		if s.Writer != nil {
-			_, err := s.Writer.Write(full)
-			if err != nil {
-				s.SetError(err)
-			}
-		}
 		if s.bufferSize > 0 {
 			s.storeEvent(id, full)
 		}
-		return
-	}
-	if s.Writer != nil {
-		_, err := s.Writer.Write(framed)
-		if err != nil {
-			s.SetError(err)
-		}
+		return full
 	}
 	if s.bufferSize > 0 {
 		id := atomic.AddUint64(&s.RequestIdSeq, 1)
 		s.storeEvent(id, framed)
 	}
+	return framed
+}
+
+// writeOut writes data to the currently attached writer; it returns errNoStream when session is detached.
+func (s *Session) writeOut(data []byte) error {
+	s.Mutex.Lock()
+	w := s.Writer
+	s.Mutex.Unlock()
+	if w == nil || w == io.Discard {
+		return errNoStream
+	}
+	s.writeMu.Lock()
+	_, err := w.Write(data)
+	s.writeMu.Unlock()
+	if err != nil {
+		s.SetError(err)
+	}
+	return err
+}
+
+// QueueMetrics returns outbound queue metrics; zero value when queue is not enabled.
+func (s *Session) QueueMetrics() QueueMetrics {
+	if s.queue == nil {
+		return QueueMetrics{}
+	}
+	return s.queue.metrics()
 }
 
 func (s *Session) storeEvent(id uint64, data []byte) {
-	s.events = append(s.events, event{id: id, data: append([]byte(nil), data...)})
-	if len(s.events) > s.bufferSize {
-		// handle overflow
-		if s.overflowPolicy == OverflowMark {
-			s.overflowed = true
-		}
-		// drop oldest
-		excess := len(s.events) - s.bufferSize
-		s.events = s.events[excess:]
+	before := s.events.bytes
+	if evicted, _ := s.events.push(event{id: id, data: append([]byte(nil), data...)}); evicted > 0 {
+		s.markOverflow()
+	}
+	if s.budget != nil {
+		s.budget.add(s.events.bytes - before)
 	}
 }
 
-// EventsAfter returns buffered framed messages with id greater than lastID.
-func (s *Session) EventsAfter(lastID uint64) [][]byte {
+// configureEvents applies replay buffer limits; caller must hold the session mutex.
+func (s *Session) configureEvents() {
+	before := s.events.bytes
+	if evicted, _ := s.events.configure(s.bufferSize, s.maxEventBytes); evicted > 0 {
+		s.markOverflow()
+	}
+	if s.budget != nil {
+		s.budget.add(s.events.bytes - before)
+	}
+}
+
+// markOverflow records replay buffer overflow; caller must hold the session mutex.
+func (s *Session) markOverflow() {
+	if s.overflowPolicy == OverflowMark {
+		s.overflowed = true
+	}
+	if s.lifecycle != nil && !s.overflowNotified {
+		s.overflowNotified = true
+		s.overflowPending = true
+	}
+}
+
+// notifyOverflow publishes pending overflow event; it must be called without holding the session mutex.
+func (s *Session) notifyOverflow() {
 	s.Mutex.Lock()
-	events := make([]event, len(s.events))
-	copy(events, s.events)
+	pending := s.overflowPending
+	s.overflowPending = false
+	lifecycle := s.lifecycle
 	s.Mutex.Unlock()
-	if lastID == 0 || len(events) == 0 {
-		res := make([][]byte, len(events))
-		for i, ev := range events {
-			res[i] = ev.data
-		}
-		return res
+	if pending {
+		lifecycle.Emit(SessionOverflowed, s)
 	}
-	var idx int
-	// simple linear search as buffer small
-	for idx < len(events) && events[idx].id <= lastID {
-		idx++
+}
+
+// evictEvents releases replay buffer memory to satisfy event budget; when oldestOnly is set
+// only the oldest events are dropped until the budget is met, otherwise the whole buffer is released.
+func (s *Session) evictEvents(oldestOnly bool) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	if s.events.len() == 0 {
+		return
 	}
-	if idx >= len(events) {
-		return nil
+	before := s.events.bytes
+	if oldestOnly && s.budget != nil {
+		for s.events.len() > 0 && s.budget.Used()-int64(before-s.events.bytes) > s.budget.limit {
+			s.events.dropOldest()
+		}
+	} else {
+		s.events.clear()
 	}
-	res := make([][]byte, len(events)-idx)
-	for i := idx; i < len(events); i++ {
-		res[i-idx] = events[i].data
+	s.markOverflow()
+	if s.budget != nil {
+		s.budget.add(s.events.bytes - before)
 	}
-	return res
+}
+
+// enforceBudget applies server-wide event budget; it must be called without holding the session mutex.
+func (s *Session) enforceBudget() {
+	if s.budget != nil {
+		s.budget.enforce(s)
+	}
+	s.notifyOverflow()
+}
+
+// EventsAfter returns buffered framed messages with id greater than lastID.
+func (s *Session) EventsAfter(lastID uint64) [][]byte {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.events.after(lastID)
+}
+
+// Replay returns buffered framed messages with id greater than lastID together with a replay gap
+// when some of the messages following lastID were evicted from the buffer.
+func (s *Session) Replay(lastID uint64) ([][]byte, *transport.ReplayGap) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.events.after(lastID), s.events.gap(lastID)
+}
+
+// Overflowed returns true if the replay buffer overflowed under OverflowMark policy.
+func (s *Session) Overflowed() bool {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.overflowed
+}
+
+// BufferedEvents returns number of events and bytes held in the replay buffer.
+func (s *Session) BufferedEvents() (int, int) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.events.len(), s.events.bytes
 }
 
 func NewSession(ctx context.Context, id string, writer io.Writer, newHandler transport.NewHandler, options ...Option) *Session {
@@ -212,6 +373,7 @@ func NewSession(ctx context.Context, id string, writer io.Writer, newHandler tra
 		LastSeen:      time.Now(),
 		State:         SessionStateActive,
 		WriterPresent: writer != nil,
+		streamDone:    make(chan struct{}),
 	}
 	ret.Handler = newHandler(ctx, NewTransport(ret.RoundTrips, ret.SendData, ret))
 	for _, option := range options {
@@ -229,6 +391,135 @@ const (
 	SessionStateClosed
 )
 
+// Release frees resources bound to the session lifetime; it is called when session is removed from the store.
+func (s *Session) Release() {
+	s.Mutex.Lock()
+	if atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		s.State = SessionStateClosed
+		if s.released == nil {
+			s.released = make(chan struct{})
+		}
+		close(s.released)
+	}
+	s.closeStream()
+	s.Mutex.Unlock()
+	s.Attributes.Clear()
+	s.Mutex.Lock()
+	_, size := s.events.clear()
+	budget := s.budget
+	s.Mutex.Unlock()
+	if budget != nil {
+		budget.add(-size)
+		budget.unregister(s)
+	}
+	if s.queue != nil {
+		s.queue.close()
+	}
+}
+
+// TTL returns per-session lifecycle overrides
+func (s *Session) TTL() SessionTTL {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.ttl
+}
+
+// SetTTL overrides lifecycle timeouts for the session, e.g. to keep a privileged session longer
+func (s *Session) SetTTL(ttl SessionTTL) {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	s.ttl = ttl
+}
+
+// TripTimeout returns server-initiated round trip timeout configured for the session
+func (s *Session) TripTimeout() time.Duration {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.tripTimeout
+}
+
+// StreamAttached returns true if a client stream is attached to the session.
+func (s *Session) StreamAttached() bool {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	return s.streamAttached()
+}
+
+// streamAttached returns true if a client stream is attached and resumed; caller must hold the session mutex.
+func (s *Session) streamAttached() bool {
+	return s.Writer != nil && s.Writer != io.Discard && s.State == SessionStateActive && !s.resuming
+}
+
+// AwaitStream holds the caller while a previously attached client stream is detached (or resuming),
+// up to the detached send timeout. Sessions whose stream never attached (the GET stream is optional) are not held:
+// messages are buffered for replay. It returns NotConnectedError when the deadline passes, the session is closed or ctx is done.
+func (s *Session) AwaitStream(ctx context.Context) error {
+	s.Mutex.Lock()
+	if atomic.LoadInt32(&s.closed) == 1 {
+		s.Mutex.Unlock()
+		return &NotConnectedError{SessionID: s.Id, Reason: "session closed"}
+	}
+	if s.State == SessionStateActive && !s.resuming {
+		s.Mutex.Unlock()
+		return nil
+	}
+	if s.attached == nil {
+		s.attached = make(chan struct{})
+	}
+	if s.released == nil {
+		s.released = make(chan struct{})
+	}
+	attached, released, timeout := s.attached, s.released, s.detachedSendTimeout
+	s.Mutex.Unlock()
+	if timeout <= 0 {
+		return &NotConnectedError{SessionID: s.Id, Reason: "stream detached"}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-attached:
+		return nil
+	case <-released:
+		return &NotConnectedError{SessionID: s.Id, Reason: "session closed"}
+	case <-timer.C:
+		return &NotConnectedError{SessionID: s.Id, Reason: fmt.Sprintf("stream not attached within %v", timeout)}
+	case <-ctx.Done():
+		return &NotConnectedError{SessionID: s.Id, Reason: ctx.Err().Error()}
+	}
+}
+
+// StreamDone returns a channel closed when the current client stream is detached or should be disconnected.
+func (s *Session) StreamDone() <-chan struct{} {
+	s.Mutex.Lock()
+	defer s.Mutex.Unlock()
+	if s.streamDone == nil {
+		s.streamDone = make(chan struct{})
+	}
+	return s.streamDone
+}
+
+// Disconnect requests the transport to close the current client stream.
+func (s *Session) Disconnect() {
+	s.Mutex.Lock()
+	s.closeStream()
+	s.Mutex.Unlock()
+}
+
+// closeStream closes stream done channel; caller must hold the session mutex.
+func (s *Session) closeStream() {
+	if s.streamDone == nil {
+		return
+	}
+	select {
+	case <-s.streamDone:
+	default:
+		close(s.streamDone)
+	}
+}
+
 // Touch updates LastSeen timestamp.
 func (s *Session) Touch() {
 	s.Mutex.Lock()
@@ -236,7 +527,8 @@ func (s *Session) Touch() {
 	s.Mutex.Unlock()
 }
 
-// MarkDetached marks session as detached and records time.
+// MarkDetached marks session as detached and records time. It returns once in-flight writes to the
+// detached writer have finished, so the caller may let the underlying stream go.
 func (s *Session) MarkDetached() {
 	s.Mutex.Lock()
 	now := time.Now()
@@ -244,21 +536,46 @@ func (s *Session) MarkDetached() {
 	s.State = SessionStateDetached
 	s.WriterPresent = false
 	s.Writer = nil
+	s.closeStream()
 	s.Mutex.Unlock()
+	s.writeMu.Lock()
+	s.writeMu.Unlock()
 }
 
-// MarkActiveWithWriter re-attaches a writer and marks session active.
+// MarkActiveWithWriter re-attaches a writer, marks session active and releases held senders.
 func (s *Session) MarkActiveWithWriter(w io.Writer) {
+	s.AttachWriter(w)
+	s.Resume()
+}
+
+// AttachWriter re-attaches a writer and marks session active without releasing held senders,
+// so that missed events can be replayed first; call Resume afterwards.
+func (s *Session) AttachWriter(w io.Writer) {
 	s.Mutex.Lock()
 	s.Writer = w
 	s.WriterPresent = w != nil
+	s.closeStream()
+	s.streamDone = make(chan struct{})
 	s.State = SessionStateActive
 	s.DetachedAt = nil
+	s.overflowNotified = false
+	s.resuming = true
 	s.LastSeen = time.Now()
 	atomic.AddUint64(&s.writerGen, 1)
 	s.Mutex.Unlock()
 }
 
+// Resume releases senders held while the stream was detached.
+func (s *Session) Resume() {
+	s.Mutex.Lock()
+	s.resuming = false
+	if s.attached != nil && s.streamAttached() {
+		close(s.attached)
+		s.attached = nil
+	}
+	s.Mutex.Unlock()
+}
+
 // WriterGeneration returns the current writer attachment generation.
 func (s *Session) WriterGeneration() uint64 {
 	return atomic.LoadUint64(&s.writerGen)
@@ -273,7 +590,9 @@ func (s *Session) WriteKeepAlive(data []byte) bool {
 	if w == nil {
 		return false
 	}
+	s.writeMu.Lock()
 	_, _ = w.Write(data)
+	s.writeMu.Unlock()
 	return true
 }
 
@@ -286,6 +605,8 @@ func (s *Session) WriteBuffered(data []byte) bool {
 	if w == nil {
 		return false
 	}
+	s.writeMu.Lock()
 	_, _ = w.Write(data)
+	s.writeMu.Unlock()
 	return true
 }
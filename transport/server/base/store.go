@@ -4,6 +4,7 @@ import "github.com/viant/jsonrpc/internal/collection"
 
 // SessionStore abstracts session persistence.
 // Default implementation is in-memory; custom stores (e.g., Redis) can implement this interface.
+// Stores persisting sessions should include session Attributes and call Release on Delete.
 type SessionStore interface {
 	Get(id string) (*Session, bool)
 	Put(id string, s *Session)
@@ -18,7 +19,12 @@ type memorySessionStore struct {
 
 func (s *memorySessionStore) Get(id string) (*Session, bool) { return s.m.Get(id) }
 func (s *memorySessionStore) Put(id string, v *Session)      { s.m.Put(id, v) }
-func (s *memorySessionStore) Delete(id string)               { s.m.Delete(id) }
+func (s *memorySessionStore) Delete(id string) {
+	if aSession, ok := s.m.Get(id); ok {
+		aSession.Release()
+	}
+	s.m.Delete(id)
+}
 func (s *memorySessionStore) Range(f func(string, *Session) bool) {
 	s.m.Range(f)
 }
@@ -21,6 +21,9 @@ func (s *Transport) Notify(ctx context.Context, notification *jsonrpc.Notificati
 	if err != nil {
 		return err
 	}
+	if err = s.session.AwaitStream(ctx); err != nil {
+		return err
+	}
 	s.sendData(ctx, data)
 	return nil
 }
@@ -42,18 +45,53 @@ func (s *Transport) Send(ctx context.Context, request *jsonrpc.Request) (*jsonrp
 	if err != nil {
 		return nil, err
 	}
-	s.sendData(ctx, data)
+	if err = s.session.AwaitStream(ctx); err != nil {
+		return nil, err
+	}
+	// register trip before writing so that a fast reply is always matched
 	roundTrip, err := s.tripper.Add(request)
 	if err != nil {
 		return nil, err
 	}
-	err = roundTrip.Wait(ctx, s.TripTimeout)
-	if err != nil {
+	s.sendData(ctx, data)
+	if err = roundTrip.Wait(ctx, s.timeout(ctx)); err != nil {
+		s.tripper.Remove(roundTrip)
+		s.cancel(request.Id, err)
 		return nil, err
 	}
 	return roundTrip.Response, err
 }
 
+// timeout returns round trip timeout: per-call context override, session setting, then transport default
+func (s *Transport) timeout(ctx context.Context) time.Duration {
+	if timeout, ok := transport.TripTimeoutFromContext(ctx); ok {
+		return timeout
+	}
+	if timeout := s.session.TripTimeout(); timeout > 0 {
+		return timeout
+	}
+	return s.TripTimeout
+}
+
+// cancel notifies the client that the server abandoned pending request
+func (s *Transport) cancel(id jsonrpc.RequestId, reason error) {
+	notification, err := jsonrpc.NewNotification(transport.CancelledNotification, &transport.CancelledParams{RequestId: id, Reason: reason.Error()})
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+	// the caller context is already done, deliver on a fresh one
+	s.sendData(context.Background(), data)
+}
+
+// Connected returns true if a client stream is attached to the session.
+func (s *Transport) Connected() bool {
+	return s.session.StreamAttached()
+}
+
 // NewTransport creates a new Transport
 func NewTransport(tripper *transport.RoundTrips, sendData func(ctx context.Context, data []byte), session *Session) *Transport {
 	return &Transport{
@@ -0,0 +1,119 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// replyWriter answers every request synchronously from within Write, before Send returns from sendData.
+type replyWriter struct {
+	session *Session
+	sent    []string
+}
+
+func (w *replyWriter) Write(p []byte) (int, error) {
+	w.sent = append(w.sent, string(p))
+	request := &jsonrpc.Request{}
+	if err := json.Unmarshal(p, request); err == nil && request.Id != nil && request.Method != "" && !strings.Contains(request.Method, "hang") {
+		if trip, err := w.session.RoundTrips.Match(request.Id); err == nil {
+			trip.SetResponse(&jsonrpc.Response{Id: request.Id, Jsonrpc: "2.0", Result: []byte(`{"ok":true}`)})
+		}
+	}
+	return len(p), nil
+}
+
+func newReplySession(options ...Option) (*Session, *replyWriter, transport.Transport) {
+	writer := &replyWriter{}
+	var tr transport.Transport
+	session := NewSession(context.Background(), "s1", writer, func(ctx context.Context, t transport.Transport) transport.Handler {
+		tr = t
+		return &nopHandler{}
+	}, options...)
+	writer.session = session
+	return session, writer, tr
+}
+
+func TestTransport_Send(t *testing.T) {
+	t.Run("fast reply", func(t *testing.T) {
+		session, _, tr := newReplySession(WithTripTimeout(time.Second))
+		defer session.Release()
+		response, err := tr.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Method: "sampling/createMessage"})
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.JSONEq(t, `{"ok":true}`, string(response.Result))
+	})
+
+	t.Run("session timeout", func(t *testing.T) {
+		session, writer, tr := newReplySession(WithTripTimeout(20 * time.Millisecond))
+		defer session.Release()
+		started := time.Now()
+		_, err := tr.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Id: 7, Method: "hang"})
+		assert.True(t, errors.Is(err, transport.ErrTimeout))
+		assert.Less(t, time.Since(started), time.Second)
+		assert.Len(t, writer.sent, 2)
+		assert.Contains(t, writer.sent[1], transport.CancelledNotification)
+		assert.Contains(t, writer.sent[1], `"requestId":7`)
+		_, err = session.RoundTrips.Match(7)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("per call timeout", func(t *testing.T) {
+		session, _, tr := newReplySession(WithTripTimeout(time.Minute))
+		defer session.Release()
+		ctx := transport.WithTripTimeout(context.Background(), 20*time.Millisecond)
+		_, err := tr.Send(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Method: "hang"})
+		assert.True(t, errors.Is(err, transport.ErrTimeout))
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		session, writer, tr := newReplySession()
+		defer session.Release()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err := tr.Send(ctx, &jsonrpc.Request{Jsonrpc: "2.0", Id: 3, Method: "hang"})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Len(t, writer.sent, 2)
+		assert.Contains(t, writer.sent[1], `"reason":"context canceled"`)
+	})
+}
+
+func TestRoundTrips_Concurrent(t *testing.T) {
+	trips := transport.NewRoundTrips(8)
+	closeErr := errors.New("closed")
+	var group sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			for j := 0; j < 100; j++ {
+				trip, err := trips.Add(&jsonrpc.Request{Id: i*100 + j})
+				if err != nil {
+					continue
+				}
+				_ = trips.Pending()
+				_ = trips.Size()
+				trips.Remove(trip)
+			}
+		}(i)
+	}
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		time.Sleep(time.Millisecond)
+		trips.CloseWithError(closeErr)
+	}()
+	group.Wait()
+	_, err := trips.Add(&jsonrpc.Request{Id: 1})
+	assert.ErrorIs(t, err, closeErr)
+	assert.Equal(t, 0, trips.Pending())
+}
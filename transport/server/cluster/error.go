@@ -0,0 +1,12 @@
+package cluster
+
+import "errors"
+
+var (
+	// ErrNodeUnavailable indicates the target node is not reachable
+	ErrNodeUnavailable = errors.New("cluster node unavailable")
+	// ErrSessionNotOwned indicates no node owns the session
+	ErrSessionNotOwned = errors.New("session not owned by any node")
+	// ErrBusNotAuthenticated indicates a bus configured without peer authentication
+	ErrBusNotAuthenticated = errors.New("cluster bus not authenticated")
+)
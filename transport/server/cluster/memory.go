@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryBus is an in-process Bus
+type MemoryBus struct {
+	mux       sync.RWMutex
+	receivers map[string]Receiver
+}
+
+// Send delivers message to the node receiver
+func (b *MemoryBus) Send(ctx context.Context, node string, message *Message) error {
+	b.mux.RLock()
+	receiver, ok := b.receivers[node]
+	b.mux.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrNodeUnavailable, node)
+	}
+	copied := *message
+	go receiver(context.Background(), &copied)
+	return nil
+}
+
+// Listen registers node receiver
+func (b *MemoryBus) Listen(node string, receiver Receiver) (func(), error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if _, ok := b.receivers[node]; ok {
+		return nil, fmt.Errorf("node %v already listening", node)
+	}
+	b.receivers[node] = receiver
+	return func() {
+		b.mux.Lock()
+		defer b.mux.Unlock()
+		delete(b.receivers, node)
+	}, nil
+}
+
+// NewMemoryBus creates in-process bus
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{receivers: map[string]Receiver{}}
+}
+
+// MemoryRegistry is an in-process Registry
+type MemoryRegistry struct {
+	mux    sync.RWMutex
+	owners map[string]string
+}
+
+// Claim records node as the session owner
+func (r *MemoryRegistry) Claim(ctx context.Context, sessionID, node string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.owners[sessionID] = node
+	return nil
+}
+
+// Owner returns session owner node
+func (r *MemoryRegistry) Owner(ctx context.Context, sessionID string) (string, bool, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	node, ok := r.owners[sessionID]
+	return node, ok, nil
+}
+
+// Release removes session ownership if held by node
+func (r *MemoryRegistry) Release(ctx context.Context, sessionID, node string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.owners[sessionID] == node {
+		delete(r.owners, sessionID)
+	}
+	return nil
+}
+
+// NewMemoryRegistry creates in-process registry
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{owners: map[string]string{}}
+}
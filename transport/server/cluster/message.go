@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+// MessageKind represents cluster message kind
+type MessageKind string
+
+const (
+	// MessageInbound carries a client message (request, notification or response to a
+	// server-initiated request) received by a node that does not own the session
+	MessageInbound MessageKind = "inbound"
+	// MessageOutbound carries data to be written to the session client stream
+	MessageOutbound MessageKind = "outbound"
+	// MessageReply carries synchronous output of a forwarded inbound message
+	MessageReply MessageKind = "reply"
+)
+
+// Message represents a message exchanged between nodes
+type Message struct {
+	ID        uint64               `json:"id,omitempty"`
+	Kind      MessageKind          `json:"kind"`
+	SessionID string               `json:"sessionId"`
+	From      string               `json:"from"`
+	Data      []byte               `json:"data,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	Info      *jsonrpc.RequestInfo `json:"info,omitempty"`
+	// Principal is the bearer token principal authenticated by the forwarding node
+	Principal *auth.Principal `json:"principal,omitempty"`
+	// Grant is the BFF grant resolved by the forwarding node, without its id (the cookie value)
+	Grant *auth.Grant `json:"grant,omitempty"`
+}
+
+// Receiver handles messages delivered to a node
+type Receiver func(ctx context.Context, message *Message)
+
+// Bus delivers messages between nodes
+type Bus interface {
+	// Send delivers message to the node
+	Send(ctx context.Context, node string, message *Message) error
+	// Listen registers node receiver; the returned function stops listening
+	Listen(node string, receiver Receiver) (func(), error)
+}
+
+// Registry tracks which node owns (holds the live stream of) a session
+type Registry interface {
+	// Claim records node as the session owner
+	Claim(ctx context.Context, sessionID, node string) error
+	// Owner returns session owner node
+	Owner(ctx context.Context, sessionID string) (string, bool, error)
+	// Release removes session ownership if held by node
+	Release(ctx context.Context, sessionID, node string) error
+}
+
+// Config represents cluster membership of a server handler
+type Config struct {
+	// Node is this replica id, unique within the cluster
+	Node     string
+	Bus      Bus
+	Registry Registry
+	// Timeout bounds how long a forwarded message waits for the owner reply (default 5m)
+	Timeout time.Duration
+}
@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+// Node routes session messages between replicas: it claims ownership of sessions created locally,
+// forwards client messages for remote sessions to their owner and delivers outbound data to the owner's stream.
+type Node struct {
+	id       string
+	bus      Bus
+	registry Registry
+	handler  *base.Handler
+	timeout  time.Duration
+	seq      uint64
+	mux      sync.Mutex
+	pending  map[uint64]chan *Message
+	stop     []func()
+}
+
+// ID returns node id
+func (n *Node) ID() string {
+	return n.id
+}
+
+// Start starts receiving cluster messages and tracking session ownership
+func (n *Node) Start() error {
+	stopListening, err := n.bus.Listen(n.id, n.receive)
+	if err != nil {
+		return err
+	}
+	n.stop = append(n.stop, stopListening, n.handler.Events.Subscribe(n.onSessionEvent))
+	return nil
+}
+
+// Stop stops receiving cluster messages
+func (n *Node) Stop() {
+	for _, stop := range n.stop {
+		stop()
+	}
+	n.stop = nil
+}
+
+// Forward sends client message for a session held by another node and returns its synchronous output
+// (JSON-RPC response for requests, empty for notifications and responses).
+func (n *Node) Forward(ctx context.Context, sessionID string, data []byte, info *jsonrpc.RequestInfo) ([]byte, error) {
+	owner, err := n.owner(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	message := &Message{ID: atomic.AddUint64(&n.seq, 1), Kind: MessageInbound, SessionID: sessionID, From: n.id, Data: data}
+	if info != nil {
+		forwarded := *info
+		forwarded.Grant = nil     // carried typed in the message for authorization by the owner
+		forwarded.Principal = nil // carried typed in the message, bound to the session by the owner
+		message.Info = &forwarded
+		message.Principal, _ = info.Principal.(*auth.Principal)
+		if grant, ok := info.Grant.(*auth.Grant); ok && grant != nil {
+			redacted := *grant
+			redacted.ID = ""
+			message.Grant = &redacted
+		}
+	}
+	reply := make(chan *Message, 1)
+	n.mux.Lock()
+	n.pending[message.ID] = reply
+	n.mux.Unlock()
+	defer func() {
+		n.mux.Lock()
+		delete(n.pending, message.ID)
+		n.mux.Unlock()
+	}()
+	if err = n.bus.Send(ctx, owner, message); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(n.timeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %v: no reply within %v", ErrNodeUnavailable, owner, n.timeout)
+	case result := <-reply:
+		if result.Error != "" {
+			return nil, errors.New(result.Error)
+		}
+		return result.Data, nil
+	}
+}
+
+// Deliver writes message data to the session stream, forwarding it to the owner node when the session is remote
+func (n *Node) Deliver(ctx context.Context, sessionID string, data []byte) error {
+	if aSession, ok := n.handler.Sessions.Get(sessionID); ok {
+		aSession.SendData(ctx, data)
+		return nil
+	}
+	owner, err := n.owner(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	return n.bus.Send(ctx, owner, &Message{Kind: MessageOutbound, SessionID: sessionID, From: n.id, Data: data})
+}
+
+func (n *Node) owner(ctx context.Context, sessionID string) (string, error) {
+	owner, ok, err := n.registry.Owner(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if !ok || owner == n.id {
+		return "", fmt.Errorf("%w: %v", ErrSessionNotOwned, sessionID)
+	}
+	return owner, nil
+}
+
+func (n *Node) receive(ctx context.Context, message *Message) {
+	switch message.Kind {
+	case MessageReply:
+		n.mux.Lock()
+		reply, ok := n.pending[message.ID]
+		n.mux.Unlock()
+		if ok {
+			reply <- message
+		}
+	case MessageOutbound:
+		if aSession, ok := n.handler.Sessions.Get(message.SessionID); ok {
+			aSession.SendData(ctx, message.Data)
+		}
+	case MessageInbound:
+		reply := &Message{ID: message.ID, Kind: MessageReply, SessionID: message.SessionID, From: n.id}
+		if aSession, ok := n.handler.Sessions.Get(message.SessionID); ok {
+			if !auth.BindSession(aSession, message.Principal) {
+				reply.Error = fmt.Sprintf("session '%s' is bound to a different principal", message.SessionID)
+				_ = n.bus.Send(ctx, message.From, reply)
+				return
+			}
+			ctx = context.WithValue(ctx, jsonrpc.SessionKey, aSession)
+			if message.Principal != nil {
+				ctx = auth.WithPrincipal(ctx, message.Principal)
+			}
+			if message.Info != nil {
+				message.Info.Principal = message.Principal
+				if message.Grant != nil {
+					message.Info.Grant = message.Grant
+				}
+				ctx = jsonrpc.WithRequestInfo(ctx, message.Info)
+			}
+			output := bytes.Buffer{}
+			n.handler.HandleMessage(ctx, aSession, message.Data, &output)
+			reply.Data = output.Bytes()
+		} else {
+			reply.Error = fmt.Sprintf("session '%s' not found", message.SessionID)
+		}
+		_ = n.bus.Send(ctx, message.From, reply)
+	}
+}
+
+// onSessionEvent keeps session ownership in sync with the local session store
+func (n *Node) onSessionEvent(event *base.SessionEvent) {
+	ctx := context.Background()
+	switch event.Type {
+	case base.SessionCreated, base.SessionAttached, base.SessionReattached:
+		_ = n.registry.Claim(ctx, event.SessionID, n.id)
+	case base.SessionExpired, base.SessionDeleted:
+		_ = n.registry.Release(ctx, event.SessionID, n.id)
+	}
+}
+
+// NodeOption represents node option
+type NodeOption func(n *Node)
+
+// WithForwardTimeout sets how long Forward waits for the owner reply (default 5m)
+func WithForwardTimeout(timeout time.Duration) NodeOption {
+	return func(n *Node) {
+		if timeout > 0 {
+			n.timeout = timeout
+		}
+	}
+}
+
+// NewNode creates cluster node for handler sessions
+func NewNode(id string, bus Bus, registry Registry, handler *base.Handler, options ...NodeOption) *Node {
+	ret := &Node{
+		id:       id,
+		bus:      bus,
+		registry: registry,
+		handler:  handler,
+		timeout:  5 * time.Minute,
+		pending:  map[uint64]chan *Message{},
+	}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
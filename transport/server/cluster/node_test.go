@@ -0,0 +1,117 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+type echoHandler struct{}
+
+func (h *echoHandler) Serve(ctx context.Context, request *jsonrpc.Request, response *jsonrpc.Response) {
+	info, _ := jsonrpc.RequestInfoFromContext(ctx)
+	response.Result = []byte(`{"method":"` + request.Method + `","transport":"` + info.Transport + `"}`)
+}
+
+func (h *echoHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestNode(t *testing.T) {
+	var testCases = []struct {
+		description string
+		newBus      func() Bus
+	}{
+		{description: "memory", newBus: func() Bus { return NewMemoryBus() }},
+		{description: "tcp", newBus: func() Bus {
+			return NewTCPBus(map[string]string{"a": "127.0.0.1:0", "b": "127.0.0.1:0"}, WithSharedSecret(testSecret))
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			bus := testCase.newBus()
+			if closer, ok := bus.(io.Closer); ok {
+				defer closer.Close()
+			}
+			registry := NewMemoryRegistry()
+			handlerA, handlerB := base.NewHandler(), base.NewHandler()
+			nodeA := NewNode("a", bus, registry, handlerA)
+			nodeB := NewNode("b", bus, registry, handlerB)
+			if !assert.Nil(t, nodeA.Start()) || !assert.Nil(t, nodeB.Start()) {
+				return
+			}
+			defer nodeA.Stop()
+			defer nodeB.Stop()
+
+			writer := &syncBuffer{}
+			var tr transport.Transport
+			aSession := base.NewSession(context.Background(), "s1", writer, func(ctx context.Context, t transport.Transport) transport.Handler {
+				tr = t
+				return &echoHandler{}
+			})
+			handlerA.Sessions.Put(aSession.Id, aSession)
+			handlerA.Events.Emit(base.SessionCreated, aSession)
+			owner, ok, _ := registry.Owner(context.Background(), "s1")
+			assert.True(t, ok)
+			assert.Equal(t, "a", owner)
+
+			// request landing on non-owner is served by the owner
+			output, err := nodeB.Forward(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), &jsonrpc.RequestInfo{Transport: jsonrpc.TransportStreamable})
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Contains(t, string(output), `"method":"tools/list"`)
+			assert.Contains(t, string(output), `"transport":"streamable"`)
+
+			// outbound data is written to the owner's stream
+			assert.Nil(t, nodeB.Deliver(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","method":"notifications/message"}`)))
+			assert.Eventually(t, func() bool { return bytes.Contains([]byte(writer.String()), []byte("notifications/message")) }, time.Second, 10*time.Millisecond)
+
+			// client response to a server-initiated request lands on non-owner
+			done := make(chan *jsonrpc.Response, 1)
+			go func() {
+				response, _ := tr.Send(context.Background(), &jsonrpc.Request{Jsonrpc: "2.0", Id: 100, Method: "sampling/createMessage"})
+				done <- response
+			}()
+			assert.Eventually(t, func() bool { return aSession.RoundTrips.Pending() == 1 }, time.Second, 5*time.Millisecond)
+			output, err = nodeB.Forward(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","id":100,"result":{"ok":true}}`), nil)
+			assert.Nil(t, err)
+			assert.Empty(t, output)
+			select {
+			case response := <-done:
+				if assert.NotNil(t, response) {
+					assert.JSONEq(t, `{"ok":true}`, string(response.Result))
+				}
+			case <-time.After(time.Second):
+				t.Fatal("response was not routed to owner")
+			}
+
+			handlerA.Events.Emit(base.SessionDeleted, aSession)
+			_, err = nodeB.Forward(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`), nil)
+			assert.ErrorIs(t, err, ErrSessionNotOwned)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TCPBus is a Bus exchanging newline-delimited JSON messages over TCP connections.
+// Each node listens on its peer address; outbound connections are dialed lazily and reused.
+//
+// Forwarded messages carry the caller principal and grant, so peers must be authenticated: the bus requires
+// a shared secret (every message is HMAC signed, timestamped and rejected when stale or replayed) and/or
+// mutual TLS, and refuses to listen or send without either.
+type TCPBus struct {
+	mux          sync.Mutex
+	peers        map[string]string
+	conns        map[string]*peerConn
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	secret       []byte
+	tlsConfig    *tls.Config
+	maxSkew      time.Duration
+	seenMux      sync.Mutex
+	seen         map[string]time.Time
+	nextPurge    time.Time
+}
+
+// peerConn is an outbound connection to a node; its mutex serializes dial and writes to that node only
+type peerConn struct {
+	mux  sync.Mutex
+	conn net.Conn
+}
+
+// TCPOption represents TCP bus option
+type TCPOption func(b *TCPBus)
+
+// WithSharedSecret signs every message with HMAC-SHA256 using secret (at least 32 bytes) shared by all nodes
+func WithSharedSecret(secret []byte) TCPOption {
+	return func(b *TCPBus) { b.secret = secret }
+}
+
+// WithTLS runs the bus over mutual TLS; config must hold the node certificate, RootCAs and ClientCAs,
+// and require and verify client certificates (tls.RequireAndVerifyClientCert)
+func WithTLS(config *tls.Config) TCPOption {
+	return func(b *TCPBus) { b.tlsConfig = config }
+}
+
+// WithMaxSkew sets how old a signed message may be (default 30s); it bounds the replay cache as well
+func WithMaxSkew(maxSkew time.Duration) TCPOption {
+	return func(b *TCPBus) { b.maxSkew = maxSkew }
+}
+
+// WithWriteTimeout bounds how long a message write to a peer may block (default 10s)
+func WithWriteTimeout(timeout time.Duration) TCPOption {
+	return func(b *TCPBus) { b.writeTimeout = timeout }
+}
+
+// envelope is the wire format of a message: send time, message and HMAC of both
+type envelope struct {
+	Time      int64           `json:"t"`
+	Message   json.RawMessage `json:"m"`
+	Signature []byte          `json:"s,omitempty"`
+}
+
+// authenticated returns an error unless the bus authenticates peers
+func (b *TCPBus) authenticated() error {
+	if len(b.secret) > 0 && len(b.secret) < 32 {
+		return fmt.Errorf("%w: shared secret must be at least 32 bytes", ErrBusNotAuthenticated)
+	}
+	if len(b.secret) > 0 {
+		return nil
+	}
+	if b.tlsConfig != nil && b.tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+		return nil
+	}
+	return fmt.Errorf("%w: configure WithSharedSecret or WithTLS (mutual TLS)", ErrBusNotAuthenticated)
+}
+
+func (b *TCPBus) sign(at int64, message []byte) []byte {
+	if len(b.secret) == 0 {
+		return nil
+	}
+	hash := hmac.New(sha256.New, b.secret)
+	hash.Write([]byte(strconv.FormatInt(at, 10)))
+	hash.Write([]byte{'.'})
+	hash.Write(message)
+	return hash.Sum(nil)
+}
+
+// encode returns signed wire line of message
+func (b *TCPBus) encode(message *Message) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	at := time.Now().UnixNano()
+	line, err := json.Marshal(&envelope{Time: at, Message: data, Signature: b.sign(at, data)})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// decode verifies wire line signature, age and uniqueness
+func (b *TCPBus) decode(line []byte) (*Message, error) {
+	wire := &envelope{}
+	if err := json.Unmarshal(line, wire); err != nil {
+		return nil, err
+	}
+	if len(b.secret) > 0 {
+		if !hmac.Equal(wire.Signature, b.sign(wire.Time, wire.Message)) {
+			return nil, fmt.Errorf("invalid message signature")
+		}
+		sentAt := time.Unix(0, wire.Time)
+		if age := time.Since(sentAt); age > b.maxSkew || age < -b.maxSkew {
+			return nil, fmt.Errorf("stale message: %v", age)
+		}
+		if !b.firstSeen(string(wire.Signature), sentAt) {
+			return nil, fmt.Errorf("replayed message")
+		}
+	}
+	message := &Message{}
+	if err := json.Unmarshal(wire.Message, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// firstSeen records signature and returns false if it was already seen within the skew window
+func (b *TCPBus) firstSeen(signature string, sentAt time.Time) bool {
+	b.seenMux.Lock()
+	defer b.seenMux.Unlock()
+	now := time.Now()
+	b.purgeSeen(now)
+	if expiry, ok := b.seen[signature]; ok && !now.After(expiry) {
+		return false
+	}
+	b.seen[signature] = sentAt.Add(b.maxSkew)
+	return true
+}
+
+// purgeSeen drops expired replay cache entries at most once per skew window; caller must hold seenMux
+func (b *TCPBus) purgeSeen(now time.Time) {
+	if now.Before(b.nextPurge) {
+		return
+	}
+	b.nextPurge = now.Add(b.maxSkew)
+	for candidate, expiry := range b.seen {
+		if now.After(expiry) {
+			delete(b.seen, candidate)
+		}
+	}
+}
+
+// Addr returns node address
+func (b *TCPBus) Addr(node string) string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.peers[node]
+}
+
+// Send delivers message to the node
+func (b *TCPBus) Send(ctx context.Context, node string, message *Message) error {
+	if err := b.authenticated(); err != nil {
+		return err
+	}
+	data, err := b.encode(message)
+	if err != nil {
+		return err
+	}
+	b.mux.Lock()
+	addr, ok := b.peers[node]
+	peer, found := b.conns[node]
+	if ok && !found {
+		peer = &peerConn{}
+		b.conns[node] = peer
+	}
+	b.mux.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: unknown node %v", ErrNodeUnavailable, node)
+	}
+
+	peer.mux.Lock()
+	defer peer.mux.Unlock()
+	if peer.conn == nil {
+		if peer.conn, err = b.dial(ctx, addr); err != nil {
+			return fmt.Errorf("%w: %v: %v", ErrNodeUnavailable, node, err)
+		}
+	}
+	deadline := time.Now().Add(b.writeTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = peer.conn.SetWriteDeadline(deadline)
+	if _, err = peer.conn.Write(data); err != nil {
+		_ = peer.conn.Close()
+		peer.conn = nil
+		return fmt.Errorf("%w: %v: %v", ErrNodeUnavailable, node, err)
+	}
+	return nil
+}
+
+// dial opens connection to addr
+func (b *TCPBus) dial(ctx context.Context, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: b.dialTimeout}
+	if b.tlsConfig == nil {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	config := b.tlsConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(addr)
+	}
+	return (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, "tcp", addr)
+}
+
+// Listen starts accepting messages for the node on its peer address (use port 0 to pick a free port)
+func (b *TCPBus) Listen(node string, receiver Receiver) (func(), error) {
+	if err := b.authenticated(); err != nil {
+		return nil, err
+	}
+	b.mux.Lock()
+	addr, ok := b.peers[node]
+	b.mux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown node %v", node)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	b.mux.Lock()
+	b.peers[node] = listener.Addr().String()
+	b.mux.Unlock()
+	if b.tlsConfig != nil {
+		listener = tls.NewListener(listener, b.tlsConfig)
+	}
+
+	var wg sync.WaitGroup
+	var connMux sync.Mutex
+	accepted := map[net.Conn]bool{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connMux.Lock()
+			accepted[conn] = true
+			connMux.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b.serve(conn, receiver)
+				connMux.Lock()
+				delete(accepted, conn)
+				connMux.Unlock()
+			}()
+		}
+	}()
+	return func() {
+		_ = listener.Close()
+		connMux.Lock()
+		for conn := range accepted {
+			_ = conn.Close()
+		}
+		connMux.Unlock()
+		wg.Wait()
+	}, nil
+}
+
+func (b *TCPBus) serve(conn net.Conn, receiver Receiver) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		message, err := b.decode(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		go receiver(context.Background(), message)
+	}
+}
+
+// Close closes outbound connections
+func (b *TCPBus) Close() error {
+	b.mux.Lock()
+	conns := b.conns
+	b.conns = map[string]*peerConn{}
+	b.mux.Unlock()
+	for _, peer := range conns {
+		peer.mux.Lock()
+		if peer.conn != nil {
+			_ = peer.conn.Close()
+			peer.conn = nil
+		}
+		peer.mux.Unlock()
+	}
+	return nil
+}
+
+// NewTCPBus creates TCP bus for the given node addresses (node id -> host:port); WithSharedSecret or WithTLS is required
+func NewTCPBus(peers map[string]string, options ...TCPOption) *TCPBus {
+	ret := &TCPBus{
+		peers:        map[string]string{},
+		conns:        map[string]*peerConn{},
+		dialTimeout:  5 * time.Second,
+		writeTimeout: 10 * time.Second,
+		maxSkew:      30 * time.Second,
+		seen:         map[string]time.Time{},
+	}
+	for node, addr := range peers {
+		ret.peers[node] = addr
+	}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
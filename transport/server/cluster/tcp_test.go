@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = bytes.Repeat([]byte("s"), 32)
+
+func TestTCPBus_Authentication(t *testing.T) {
+	_, err := NewTCPBus(map[string]string{"a": "127.0.0.1:0"}).Listen("a", func(ctx context.Context, message *Message) {})
+	assert.ErrorIs(t, err, ErrBusNotAuthenticated, "unauthenticated bus must not listen")
+	_, err = NewTCPBus(map[string]string{"a": "127.0.0.1:0"}, WithSharedSecret([]byte("short"))).Listen("a", func(ctx context.Context, message *Message) {})
+	assert.ErrorIs(t, err, ErrBusNotAuthenticated, "short secret")
+
+	var mux sync.Mutex
+	var received []string
+	bus := NewTCPBus(map[string]string{"a": "127.0.0.1:0"}, WithSharedSecret(testSecret), WithMaxSkew(time.Second))
+	defer bus.Close()
+	stop, err := bus.Listen("a", func(ctx context.Context, message *Message) {
+		mux.Lock()
+		received = append(received, message.SessionID)
+		mux.Unlock()
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer stop()
+
+	signed, _ := bus.encode(&Message{Kind: MessageInbound, SessionID: "signed"})
+	forged, _ := NewTCPBus(nil, WithSharedSecret(bytes.Repeat([]byte("x"), 32))).encode(&Message{Kind: MessageInbound, SessionID: "forged"})
+	tampered := bytes.Replace(append([]byte(nil), signed...), []byte("signed"), []byte("tamper"), 1)
+	staleBus := NewTCPBus(nil, WithSharedSecret(testSecret))
+	stale, _ := staleBus.encode(&Message{Kind: MessageInbound, SessionID: "stale"})
+	time.Sleep(1100 * time.Millisecond)
+	fresh, _ := bus.encode(&Message{Kind: MessageInbound, SessionID: "fresh"})
+
+	conn, err := net.Dial("tcp", bus.Addr("a"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+	for _, line := range [][]byte{forged, tampered, stale, fresh, fresh} {
+		_, err = conn.Write(line)
+		assert.Nil(t, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, []string{"fresh"}, received, "forged, tampered, stale and replayed messages are dropped")
+}
+
+func TestTCPBus_StalledPeer(t *testing.T) {
+	stalled, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer stalled.Close()
+	go func() {
+		for {
+			conn, err := stalled.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() // never read, so the sender's TCP window fills up
+		}
+	}()
+
+	received := make(chan string, 1)
+	bus := NewTCPBus(map[string]string{"a": "127.0.0.1:0", "stalled": stalled.Addr().String()}, WithSharedSecret(testSecret), WithWriteTimeout(200*time.Millisecond))
+	defer bus.Close()
+	stop, err := bus.Listen("a", func(ctx context.Context, message *Message) { received <- message.SessionID })
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer stop()
+
+	stalledErr := make(chan error, 1)
+	go func() {
+		payload := &Message{Kind: MessageOutbound, SessionID: "bulk", Data: bytes.Repeat([]byte("x"), 1024*1024)}
+		for {
+			if err := bus.Send(context.Background(), "stalled", payload); err != nil {
+				stalledErr <- err
+				return
+			}
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, bus.Send(context.Background(), "a", &Message{Kind: MessageOutbound, SessionID: "other"}))
+	select {
+	case id := <-received:
+		assert.Equal(t, "other", id, "a stalled peer must not block other peers")
+	case <-time.After(time.Second):
+		assert.Fail(t, "message to healthy peer not delivered")
+	}
+	select {
+	case err := <-stalledErr:
+		assert.ErrorIs(t, err, ErrNodeUnavailable, "write to stalled peer times out")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "write to stalled peer did not time out")
+	}
+}
@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Authorizer decides whether the request may access the admin endpoint
+type Authorizer func(r *http.Request) bool
+
+// BearerToken returns authorizer accepting requests with the given static bearer token
+func BearerToken(token string) Authorizer {
+	return func(r *http.Request) bool {
+		value := r.Header.Get("Authorization")
+		if token == "" || !strings.HasPrefix(value, "Bearer ") {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(value, "Bearer ")), []byte(token)) == 1
+	}
+}
+
+// AllowAll returns authorizer accepting every request; use only behind a trusted network boundary
+func AllowAll() Authorizer {
+	return func(r *http.Request) bool { return true }
+}
@@ -0,0 +1,167 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+const defaultPrefix = "/admin"
+
+// Handler exposes session inspection and control over a session store:
+//
+//	GET    {prefix}/sessions             – list sessions
+//	GET    {prefix}/sessions/{id}        – session details
+//	POST   {prefix}/sessions/{id}/detach – force-detach client stream (client may reconnect)
+//	DELETE {prefix}/sessions/{id}        – force-close session
+//	POST   {prefix}/sessions/{id}/notify – send JSON-RPC notification to the session
+//	POST   {prefix}/notify               – broadcast JSON-RPC notification to all sessions
+type Handler struct {
+	sessions   base.SessionStore
+	lifecycle  *base.Lifecycle
+	authorizer Authorizer
+	prefix     string
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.authorizer == nil || !h.authorizer(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, h.prefix), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "sessions" && r.Method == http.MethodGet:
+		h.list(w)
+	case path == "notify" && r.Method == http.MethodPost:
+		h.broadcast(w, r)
+	case (len(parts) == 2 || len(parts) == 3) && parts[0] == "sessions" && parts[1] != "":
+		aSession, ok := h.sessions.Get(parts[1])
+		if !ok {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		action := ""
+		if len(parts) == 3 {
+			action = parts[2]
+		}
+		h.handleSession(w, r, aSession, action)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) handleSession(w http.ResponseWriter, r *http.Request, aSession *base.Session, action string) {
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, NewSessionInfo(aSession))
+		case http.MethodDelete:
+			h.close(aSession.Id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case "detach":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aSession.Disconnect()
+		w.WriteHeader(http.StatusNoContent)
+	case "notify":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		notification, err := decodeNotification(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = aSession.Notify(r.Context(), notification); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) list(w http.ResponseWriter) {
+	var infos []*SessionInfo
+	h.sessions.Range(func(id string, aSession *base.Session) bool {
+		infos = append(infos, NewSessionInfo(aSession))
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+	if infos == nil {
+		infos = []*SessionInfo{}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (h *Handler) broadcast(w http.ResponseWriter, r *http.Request) {
+	notification, err := decodeNotification(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	delivered := 0
+	h.sessions.Range(func(id string, aSession *base.Session) bool {
+		if err := aSession.Notify(r.Context(), notification); err == nil {
+			delivered++
+		}
+		return true
+	})
+	writeJSON(w, http.StatusAccepted, map[string]int{"delivered": delivered})
+}
+
+// close removes session, running close hooks when lifecycle is configured
+func (h *Handler) close(id string) {
+	if h.lifecycle != nil {
+		h.lifecycle.Expire(id, base.ExpireForced)
+		return
+	}
+	h.sessions.Delete(id)
+}
+
+func decodeNotification(r *http.Request) (*jsonrpc.Notification, error) {
+	payload := struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params,omitempty"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	if payload.Method == "" {
+		return nil, errMissingMethod
+	}
+	return &jsonrpc.Notification{Jsonrpc: jsonrpc.Version, Method: payload.Method, Params: payload.Params}, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// New creates admin handler over session store
+func New(sessions base.SessionStore, options ...Option) *Handler {
+	ret := &Handler{sessions: sessions, prefix: defaultPrefix}
+	for _, option := range options {
+		option(ret)
+	}
+	ret.prefix = "/" + strings.Trim(ret.prefix, "/")
+	return ret
+}
+
+var errMissingMethod = errors.New("notification method is required")
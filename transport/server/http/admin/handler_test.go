@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+type nopHandler struct{}
+
+func (h *nopHandler) Serve(_ context.Context, _ *jsonrpc.Request, _ *jsonrpc.Response) {}
+func (h *nopHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification)        {}
+
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestHandler(t *testing.T) {
+	baseHandler := base.NewHandler()
+	writer := &syncBuffer{}
+	aSession := base.NewSession(context.Background(), "s1", writer, func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &nopHandler{}
+	}, base.WithEventBuffer(8), base.WithSSE())
+	baseHandler.Sessions.Put(aSession.Id, aSession)
+	var closed []string
+	lifecycle := base.NewLifecycle(baseHandler, base.LifecycleOptions{OnClose: []func(*base.Session){func(s *base.Session) { closed = append(closed, s.Id) }}})
+
+	handler := New(baseHandler.Sessions, WithAuthorizer(BearerToken("secret")), WithLifecycle(lifecycle))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	do := func(method, path, token, body string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err) {
+			t.FailNow()
+		}
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/admin/sessions", "", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = do(http.MethodGet, "/admin/sessions", "wrong", "")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/admin/sessions/s1/notify", "secret", `{"method":"notifications/message","params":{"text":"hi"}}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Contains(t, writer.String(), "notifications/message")
+
+	resp = do(http.MethodPost, "/admin/notify", "secret", `{"method":"notifications/tools/list_changed"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Contains(t, writer.String(), "list_changed")
+
+	resp = do(http.MethodGet, "/admin/sessions", "secret", "")
+	var infos []*SessionInfo
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&infos))
+	if assert.Len(t, infos, 1) {
+		assert.Equal(t, "s1", infos[0].ID)
+		assert.Equal(t, "active", infos[0].State)
+		assert.Equal(t, 2, infos[0].BufferedEvents)
+		assert.True(t, infos[0].WriterPresent)
+	}
+
+	for _, path := range []string{"/admin/sessions/s1/detach/x", "/admin/sessions/s1/unknown", "/admin/sessions//detach"} {
+		resp = do(http.MethodPost, path, "secret", "")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+	resp = do(http.MethodGet, "/admin/sessions/s1/detach", "secret", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	streamDone := aSession.StreamDone()
+	resp = do(http.MethodPost, "/admin/sessions/s1/detach", "secret", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, open := <-streamDone
+	assert.False(t, open)
+
+	resp = do(http.MethodDelete, "/admin/sessions/s1", "secret", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []string{"s1"}, closed)
+	resp = do(http.MethodGet, "/admin/sessions/s1", "secret", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+// SessionInfo represents session snapshot exposed to operators
+type SessionInfo struct {
+	ID              string             `json:"id"`
+	State           string             `json:"state"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	LastSeen        time.Time          `json:"lastSeen"`
+	DetachedAt      *time.Time         `json:"detachedAt,omitempty"`
+	WriterPresent   bool               `json:"writerPresent"`
+	BufferedEvents  int                `json:"bufferedEvents"`
+	BufferedBytes   int                `json:"bufferedBytes"`
+	PendingRequests int                `json:"pendingRequests"`
+	Overflowed      bool               `json:"overflowed"`
+	Queue           *base.QueueMetrics `json:"queue,omitempty"`
+}
+
+// NewSessionInfo creates session snapshot
+func NewSessionInfo(session *base.Session) *SessionInfo {
+	session.Mutex.Lock()
+	ret := &SessionInfo{
+		ID:            session.Id,
+		State:         stateName(session.State),
+		CreatedAt:     session.CreatedAt,
+		LastSeen:      session.LastSeen,
+		WriterPresent: session.WriterPresent,
+	}
+	if session.DetachedAt != nil {
+		detachedAt := *session.DetachedAt
+		ret.DetachedAt = &detachedAt
+	}
+	session.Mutex.Unlock()
+	ret.BufferedEvents, ret.BufferedBytes = session.BufferedEvents()
+	ret.Overflowed = session.Overflowed()
+	if session.RoundTrips != nil {
+		ret.PendingRequests = session.RoundTrips.Pending()
+	}
+	if metrics := session.QueueMetrics(); metrics != (base.QueueMetrics{}) {
+		ret.Queue = &metrics
+	}
+	return ret
+}
+
+func stateName(state base.SessionState) string {
+	switch state {
+	case base.SessionStateActive:
+		return "active"
+	case base.SessionStateDetached:
+		return "detached"
+	case base.SessionStateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
@@ -0,0 +1,21 @@
+package admin
+
+import "github.com/viant/jsonrpc/transport/server/base"
+
+// Option mutates Handler.
+type Option func(*Handler)
+
+// WithPrefix sets URI prefix the admin handler is mounted at (default: /admin).
+func WithPrefix(prefix string) Option {
+	return func(h *Handler) { h.prefix = prefix }
+}
+
+// WithAuthorizer sets request authorizer; without one every request is rejected.
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(h *Handler) { h.authorizer = authorizer }
+}
+
+// WithLifecycle routes force-close through the lifecycle manager so close hooks and events run.
+func WithLifecycle(lifecycle *base.Lifecycle) Option {
+	return func(h *Handler) { h.lifecycle = lifecycle }
+}
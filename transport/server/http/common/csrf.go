@@ -0,0 +1,162 @@
+package common
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+var (
+	// ErrCSRFToken indicates a missing or mismatched double-submit token
+	ErrCSRFToken = errors.New("csrf token missing or invalid")
+	// ErrCSRFHeader indicates a missing required custom header
+	ErrCSRFHeader = errors.New("csrf required header missing")
+	// ErrCSRFContentType indicates a CORS simple request content type (e.g. text/plain)
+	ErrCSRFContentType = errors.New("csrf simple request content type rejected")
+	// ErrCSRFSameSite indicates a cookie SameSite attribute weaker than required
+	ErrCSRFSameSite = errors.New("cookie SameSite weaker than required")
+)
+
+const (
+	defaultCSRFCookie = "XSRF-TOKEN"
+	defaultCSRFHeader = "X-XSRF-Token"
+)
+
+// CSRFPolicy protects cookie-authenticated unsafe requests (POST, PUT, PATCH, DELETE) against cross-site request forgery.
+// Requests that carry none of the protected cookies (e.g. bearer-token API clients) are not checked.
+type CSRFPolicy struct {
+	// DoubleSubmit requires the TokenHeader value to match the TokenCookie value
+	DoubleSubmit bool
+	// TokenCookie is the double-submit cookie name (default: XSRF-TOKEN); it is readable by scripts
+	TokenCookie string
+	// TokenHeader is the double-submit header name (default: X-XSRF-Token)
+	TokenHeader string
+	// RequiredHeaders must be present and non-empty (e.g. X-Requested-With); they force a CORS preflight
+	RequiredHeaders []string
+	// RejectSimpleRequests rejects bodies sent as text/plain, form or multipart, which browsers send cross-site without preflight
+	RejectSimpleRequests bool
+	// MinSameSite is the weakest SameSite mode allowed for session cookies (http.SameSiteLaxMode or http.SameSiteStrictMode)
+	MinSameSite http.SameSite
+}
+
+func (p *CSRFPolicy) tokenCookie() string {
+	if p.TokenCookie != "" {
+		return p.TokenCookie
+	}
+	return defaultCSRFCookie
+}
+
+func (p *CSRFPolicy) tokenHeader() string {
+	if p.TokenHeader != "" {
+		return p.TokenHeader
+	}
+	return defaultCSRFHeader
+}
+
+// Check validates unsafe request carrying any of cookies (any cookie when none given); nil policy allows all requests
+func (p *CSRFPolicy) Check(r *http.Request, cookies ...string) error {
+	if p == nil || !unsafeMethod(r.Method) || !hasCookie(r, cookies) {
+		return nil
+	}
+	if p.RejectSimpleRequests && r.Method != http.MethodDelete {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "", "text/plain", "application/x-www-form-urlencoded", "multipart/form-data":
+			return fmt.Errorf("%w: %q", ErrCSRFContentType, mediaType)
+		}
+	}
+	for _, name := range p.RequiredHeaders {
+		if r.Header.Get(name) == "" {
+			return fmt.Errorf("%w: %v", ErrCSRFHeader, name)
+		}
+	}
+	if p.DoubleSubmit {
+		ck, err := r.Cookie(p.tokenCookie())
+		header := r.Header.Get(p.tokenHeader())
+		if err != nil || ck.Value == "" || subtle.ConstantTimeCompare([]byte(ck.Value), []byte(header)) != 1 {
+			return ErrCSRFToken
+		}
+	}
+	return nil
+}
+
+// IssueToken sets the double-submit cookie when the request has none and returns the token
+func (p *CSRFPolicy) IssueToken(w http.ResponseWriter, r *http.Request) string {
+	if ck, err := r.Cookie(p.tokenCookie()); err == nil && ck.Value != "" {
+		return ck.Value
+	}
+	data := make([]byte, 32)
+	_, _ = rand.Read(data)
+	token := base64.RawURLEncoding.EncodeToString(data)
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.tokenCookie(),
+		Value:    token,
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// Reject writes 403 for a failed check; for a missing double-submit token a new token cookie is issued so the client can retry
+func (p *CSRFPolicy) Reject(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrCSRFToken) {
+		p.IssueToken(w, r)
+	}
+	http.Error(w, err.Error(), http.StatusForbidden)
+}
+
+// ValidateSameSite returns an error when cookie SameSite mode is weaker than MinSameSite
+func (p *CSRFPolicy) ValidateSameSite(name string, sameSite http.SameSite) error {
+	if p == nil || p.MinSameSite == 0 || sameSiteRank(sameSite) >= sameSiteRank(p.MinSameSite) {
+		return nil
+	}
+	return fmt.Errorf("%w: cookie %v", ErrCSRFSameSite, name)
+}
+
+// SameSite returns sameSite raised to MinSameSite
+func (p *CSRFPolicy) SameSite(sameSite http.SameSite) http.SameSite {
+	if p.ValidateSameSite("", sameSite) != nil {
+		return p.MinSameSite
+	}
+	return sameSite
+}
+
+func sameSiteRank(sameSite http.SameSite) int {
+	switch sameSite {
+	case http.SameSiteStrictMode:
+		return 3
+	case http.SameSiteLaxMode:
+		return 2
+	case http.SameSiteDefaultMode:
+		return 1
+	}
+	return 0
+}
+
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+func hasCookie(r *http.Request, names []string) bool {
+	if len(names) == 0 {
+		return len(r.Cookies()) > 0
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, err := r.Cookie(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
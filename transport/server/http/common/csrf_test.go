@@ -0,0 +1,94 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFPolicy_Check(t *testing.T) {
+	var testCases = []struct {
+		description string
+		policy      *CSRFPolicy
+		method      string
+		contentType string
+		cookies     map[string]string
+		headers     map[string]string
+		expectErr   error
+	}{
+		{description: "nil policy allows", method: http.MethodPost, cookies: map[string]string{"sid": "1"}},
+		{description: "safe method not checked", policy: &CSRFPolicy{DoubleSubmit: true}, method: http.MethodGet, cookies: map[string]string{"sid": "1"}},
+		{description: "request without session cookie not checked", policy: &CSRFPolicy{DoubleSubmit: true}, method: http.MethodPost, cookies: map[string]string{"other": "1"}},
+		{description: "text/plain rejected", policy: &CSRFPolicy{RejectSimpleRequests: true}, method: http.MethodPost, contentType: "text/plain;charset=UTF-8", cookies: map[string]string{"sid": "1"}, expectErr: ErrCSRFContentType},
+		{description: "form rejected", policy: &CSRFPolicy{RejectSimpleRequests: true}, method: http.MethodPost, contentType: "application/x-www-form-urlencoded", cookies: map[string]string{"sid": "1"}, expectErr: ErrCSRFContentType},
+		{description: "json allowed", policy: &CSRFPolicy{RejectSimpleRequests: true}, method: http.MethodPost, contentType: "application/json", cookies: map[string]string{"sid": "1"}},
+		{description: "required header missing", policy: &CSRFPolicy{RequiredHeaders: []string{"X-Requested-With"}}, method: http.MethodPost, cookies: map[string]string{"sid": "1"}, expectErr: ErrCSRFHeader},
+		{description: "required header present", policy: &CSRFPolicy{RequiredHeaders: []string{"X-Requested-With"}}, method: http.MethodDelete, cookies: map[string]string{"sid": "1"}, headers: map[string]string{"X-Requested-With": "fetch"}},
+		{description: "double submit missing token", policy: &CSRFPolicy{DoubleSubmit: true}, method: http.MethodPost, cookies: map[string]string{"sid": "1"}, expectErr: ErrCSRFToken},
+		{description: "double submit mismatch", policy: &CSRFPolicy{DoubleSubmit: true}, method: http.MethodPost, cookies: map[string]string{"sid": "1", "XSRF-TOKEN": "abc"}, headers: map[string]string{"X-XSRF-Token": "abd"}, expectErr: ErrCSRFToken},
+		{description: "double submit match", policy: &CSRFPolicy{DoubleSubmit: true}, method: http.MethodPost, cookies: map[string]string{"sid": "1", "XSRF-TOKEN": "abc"}, headers: map[string]string{"X-XSRF-Token": "abc"}},
+		{description: "custom token names", policy: &CSRFPolicy{DoubleSubmit: true, TokenCookie: "csrf", TokenHeader: "X-CSRF"}, method: http.MethodPost, cookies: map[string]string{"sid": "1", "csrf": "abc"}, headers: map[string]string{"X-CSRF": "abc"}},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(testCase.method, "/mcp", strings.NewReader("{}"))
+		if testCase.contentType != "" {
+			r.Header.Set("Content-Type", testCase.contentType)
+		}
+		for name, value := range testCase.cookies {
+			r.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		for name, value := range testCase.headers {
+			r.Header.Set(name, value)
+		}
+		err := testCase.policy.Check(r, "sid")
+		if testCase.expectErr != nil {
+			assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+			continue
+		}
+		assert.Nil(t, err, testCase.description)
+	}
+}
+
+func TestCSRFPolicy_SameSite(t *testing.T) {
+	var testCases = []struct {
+		description string
+		policy      *CSRFPolicy
+		sameSite    http.SameSite
+		expect      http.SameSite
+		expectErr   bool
+	}{
+		{description: "nil policy keeps mode", sameSite: http.SameSiteNoneMode, expect: http.SameSiteNoneMode},
+		{description: "none raised to lax", policy: &CSRFPolicy{MinSameSite: http.SameSiteLaxMode}, sameSite: http.SameSiteNoneMode, expect: http.SameSiteLaxMode, expectErr: true},
+		{description: "default raised to lax", policy: &CSRFPolicy{MinSameSite: http.SameSiteLaxMode}, sameSite: http.SameSiteDefaultMode, expect: http.SameSiteLaxMode, expectErr: true},
+		{description: "strict satisfies lax", policy: &CSRFPolicy{MinSameSite: http.SameSiteLaxMode}, sameSite: http.SameSiteStrictMode, expect: http.SameSiteStrictMode},
+		{description: "lax raised to strict", policy: &CSRFPolicy{MinSameSite: http.SameSiteStrictMode}, sameSite: http.SameSiteLaxMode, expect: http.SameSiteStrictMode, expectErr: true},
+	}
+	for _, testCase := range testCases {
+		err := testCase.policy.ValidateSameSite("sid", testCase.sameSite)
+		assert.Equal(t, testCase.expectErr, err != nil, testCase.description)
+		assert.Equal(t, testCase.expect, testCase.policy.SameSite(testCase.sameSite), testCase.description)
+	}
+}
+
+func TestCSRFPolicy_IssueToken(t *testing.T) {
+	policy := &CSRFPolicy{DoubleSubmit: true}
+	w := httptest.NewRecorder()
+	token := policy.IssueToken(w, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	assert.NotEmpty(t, token)
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "XSRF-TOKEN", cookies[0].Name)
+		assert.Equal(t, token, cookies[0].Value)
+		assert.False(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	r.AddCookie(&http.Cookie{Name: "XSRF-TOKEN", Value: token})
+	w = httptest.NewRecorder()
+	assert.Equal(t, token, policy.IssueToken(w, r))
+	assert.Empty(t, w.Result().Cookies())
+}
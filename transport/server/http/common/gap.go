@@ -0,0 +1,43 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+// FrameReplayGap formats replay gap as a dedicated SSE event (without id, so client resume position is kept).
+func FrameReplayGap(gap *transport.ReplayGap) []byte {
+	data, _ := json.Marshal(gap)
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", transport.ReplayGapEvent, data))
+}
+
+// Reattach attaches writer to the session, replays events after lastEventID (Last-Event-ID header value, "" for none)
+// and only then releases senders held while the stream was detached, so the client receives events in order.
+// It returns the session event to publish (SessionAttached or SessionReattached with the replayed count).
+func Reattach(aSession *base.Session, writer io.Writer, lastEventID string) *base.SessionEvent {
+	aSession.AttachWriter(writer)
+	defer aSession.Resume()
+	event := base.NewSessionEvent(base.SessionAttached, aSession)
+	lastID, err := strconv.ParseUint(strings.TrimSpace(lastEventID), 10, 64)
+	if err != nil {
+		return event
+	}
+	event.Type = base.SessionReattached
+	msgs, gap := aSession.Replay(lastID)
+	if gap != nil {
+		aSession.WriteBuffered(FrameReplayGap(gap))
+	}
+	for _, m := range msgs {
+		if !aSession.WriteBuffered(m) {
+			break
+		}
+		event.Replayed++
+	}
+	return event
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/viant/jsonrpc"
+)
+
+// ProtocolVersionHeader is the header carrying the negotiated MCP protocol version.
+const ProtocolVersionHeader = "MCP-Protocol-Version"
+
+// NewRequestInfo builds request info for the HTTP request, forwarding only allow-listed headers.
+func NewRequestInfo(r *http.Request, transportKind, sessionID string, forwardHeaders []string) *jsonrpc.RequestInfo {
+	info := &jsonrpc.RequestInfo{
+		Transport: transportKind,
+		SessionID: sessionID,
+	}
+	if r == nil {
+		return info
+	}
+	info.RemoteAddr = r.RemoteAddr
+	info.ProtocolVersion = r.Header.Get(ProtocolVersionHeader)
+	if len(forwardHeaders) > 0 {
+		info.Header = make(http.Header, len(forwardHeaders))
+		for _, name := range forwardHeaders {
+			if values := r.Header.Values(name); len(values) > 0 {
+				info.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+		}
+	}
+	return info
+}
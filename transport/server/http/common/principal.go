@@ -0,0 +1,34 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+// BindPrincipal binds session to the request principal on first use; it returns false when the session
+// is bound to a different principal. Requests without a principal (bearer auth disabled) are not checked.
+func BindPrincipal(aSession *base.Session, r *http.Request) bool {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	return auth.BindSession(aSession, principal)
+}
+
+// Authenticate verifies request bearer token; on success it returns the request with the principal in its context,
+// otherwise it writes the 401 challenge and returns nil. Requests pass through unchanged when authenticator is nil.
+func Authenticate(w http.ResponseWriter, r *http.Request, authenticator *auth.BearerAuthenticator) *http.Request {
+	if authenticator == nil || r.Method == http.MethodOptions {
+		return r
+	}
+	principal, err := authenticator.Authenticate(r)
+	if err != nil {
+		authenticator.Challenge(w, r, err)
+		return nil
+	}
+	return r.WithContext(auth.WithPrincipal(r.Context(), principal))
+}
+
+// ErrorPrincipalMismatch writes response rejecting access to a session bound to a different principal
+func ErrorPrincipalMismatch(w http.ResponseWriter) {
+	http.Error(w, "session is bound to a different principal", http.StatusForbidden)
+}
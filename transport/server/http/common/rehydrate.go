@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+type rehydratedKey struct{}
+
+// WithRehydratedAuthID returns request whose context carries the rotated grant id ("" when the grant was rejected),
+// so the rest of the request resolves the new grant instead of presenting the rotated cookie value again.
+func WithRehydratedAuthID(r *http.Request, id string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), rehydratedKey{}, id))
+}
+
+// RehydratedAuthID returns grant id rehydrated earlier in this request
+func RehydratedAuthID(r *http.Request) (string, bool) {
+	id, ok := r.Context().Value(rehydratedKey{}).(string)
+	return id, ok
+}
+
+// RehydrateGrant validates the BFF grant referenced by authID against the device binding, touches and rotates it.
+// It returns the id to store in the auth cookie. On a binding mismatch in strict mode the grant family is revoked
+// and an error matching auth.ErrBindingMismatch is returned; a rotated id replayed after its grace window returns
+// auth.ErrGrantReused (the store revokes the family) and a missing grant auth.ErrNotFound.
+func RehydrateGrant(r *http.Request, store auth.Store, authID string, binding *auth.DeviceBinding) (string, error) {
+	ctx := r.Context()
+	g, err := store.Get(ctx, authID)
+	if err != nil {
+		return "", err
+	}
+	if g == nil {
+		return "", auth.ErrNotFound
+	}
+	if event := binding.Check(r, g); event != nil && event.Revoked {
+		_ = store.RevokeFamily(ctx, g.FamilyID)
+		return "", fmt.Errorf("%w: grant family %v revoked", auth.ErrBindingMismatch, g.FamilyID)
+	}
+	_ = store.Touch(ctx, authID, time.Now())
+	rotated := &auth.Grant{Subject: g.Subject, Scopes: g.Scopes, UAHash: g.UAHash, IPHint: g.IPHint, FamilyID: g.FamilyID}
+	if binding != nil {
+		binding.Bind(r, rotated)
+	}
+	newID, err := store.Rotate(ctx, authID, rotated)
+	if err != nil || newID == "" {
+		return authID, nil
+	}
+	return newID, nil
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+func TestRehydrateGrant(t *testing.T) {
+	newRequest := func(remoteAddr string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", "browser/1.0")
+		return r
+	}
+	var testCases = []struct {
+		description string
+		mode        auth.BindingMode
+		remoteAddr  string
+		expectErr   error
+		expectAudit int
+	}{
+		{description: "same device rotates", mode: auth.BindingStrict, remoteAddr: "203.0.113.10:1"},
+		{description: "strict mismatch revokes family", mode: auth.BindingStrict, remoteAddr: "198.51.100.1:1", expectErr: auth.ErrBindingMismatch, expectAudit: 1},
+		{description: "warn mismatch keeps grant", mode: auth.BindingWarn, remoteAddr: "198.51.100.1:1", expectAudit: 1},
+		{description: "off ignores mismatch", mode: auth.BindingOff, remoteAddr: "198.51.100.1:1"},
+	}
+	for _, testCase := range testCases {
+		ctx := context.Background()
+		store := auth.NewMemoryStore(time.Hour, 24*time.Hour, 0)
+		grant := auth.NewGrant("alice")
+		audit := 0
+		binding := &auth.DeviceBinding{Mode: testCase.mode, OnMismatch: func(event *auth.BindingEvent) { audit++ }}
+		binding.Bind(newRequest("203.0.113.10:1"), grant)
+		assert.Nil(t, store.Put(ctx, grant), testCase.description)
+
+		newID, err := RehydrateGrant(newRequest(testCase.remoteAddr), store, grant.ID, binding)
+		assert.Equal(t, testCase.expectAudit, audit, testCase.description)
+		if testCase.expectErr != nil {
+			assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+			_, err = store.Get(ctx, grant.ID)
+			assert.ErrorIs(t, err, auth.ErrNotFound, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.NotEqual(t, grant.ID, newID, testCase.description)
+		rotated, err := store.Get(ctx, newID)
+		if assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, grant.UAHash, rotated.UAHash, testCase.description)
+			assert.Equal(t, "203.0.113.0/24", rotated.IPHint, testCase.description)
+		}
+	}
+
+	_, err := RehydrateGrant(newRequest("203.0.113.10:1"), auth.NewMemoryStore(time.Hour, time.Hour, 0), "missing", nil)
+	assert.ErrorIs(t, err, auth.ErrNotFound)
+}
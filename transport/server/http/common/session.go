@@ -0,0 +1,91 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/http/session"
+)
+
+// SessionIDs issues and verifies signed session ids bound to the bearer principal, else the BFF grant family.
+// A nil Signer disables signing: New returns "" (random id) and Verify accepts any id.
+type SessionIDs struct {
+	// Signer signs session ids
+	Signer *session.Signer
+	// Store resolves the BFF grant referenced by AuthCookie
+	Store auth.Store
+	// AuthCookie is the name of the cookie carrying the BFF grant id
+	AuthCookie string
+}
+
+// Fingerprint returns identity bound into signed session ids: bearer principal, else BFF grant family
+func (s *SessionIDs) Fingerprint(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.Fingerprint()
+	}
+	if g := AuthGrant(r, s.Store, s.AuthCookie); g != nil {
+		return "grant:" + g.FamilyID
+	}
+	return ""
+}
+
+// New returns signed session id, or "" (random id) when session signing is disabled
+func (s *SessionIDs) New(r *http.Request) (string, error) {
+	if s.Signer == nil {
+		return "", nil
+	}
+	return s.Signer.Sign(s.Fingerprint(r))
+}
+
+// Verify writes 403 and returns false when id is not signed for the request principal
+func (s *SessionIDs) Verify(w http.ResponseWriter, r *http.Request, id string) bool {
+	if s.Signer == nil {
+		return true
+	}
+	if _, err := s.Signer.Verify(id, s.Fingerprint(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// AuthCookieValue returns the BFF grant id rehydrated earlier in this request, else the value of the named cookie
+func AuthCookieValue(r *http.Request, name string) string {
+	if name == "" {
+		return ""
+	}
+	if id, ok := RehydratedAuthID(r); ok {
+		return id
+	}
+	if ck, err := r.Cookie(name); err == nil {
+		return ck.Value
+	}
+	return ""
+}
+
+// AuthGrant returns the BFF grant referenced by the named auth cookie, or nil when absent or store is nil
+func AuthGrant(r *http.Request, store auth.Store, name string) *auth.Grant {
+	if store == nil {
+		return nil
+	}
+	authID := AuthCookieValue(r, name)
+	if authID == "" {
+		return nil
+	}
+	g, err := store.Get(r.Context(), authID)
+	if err != nil {
+		return nil
+	}
+	return g
+}
+
+// CSRFCookies returns the non-empty names of cookies that authenticate requests
+func CSRFCookies(names ...string) []string {
+	var ret []string
+	for _, name := range names {
+		if name != "" {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/http/session"
+)
+
+func TestSessionIDs(t *testing.T) {
+	keyring, err := session.NewKeyring(&session.Key{ID: "k1", Secret: bytes.Repeat([]byte("k"), 32)})
+	if !assert.Nil(t, err) {
+		return
+	}
+	store := auth.NewMemoryStore(time.Hour, 24*time.Hour, 0)
+	grant := auth.NewGrant("alice")
+	assert.Nil(t, store.Put(context.Background(), grant))
+	other := auth.NewGrant("bob")
+	assert.Nil(t, store.Put(context.Background(), other))
+
+	newRequest := func(principal *auth.Principal, grantID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if principal != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		}
+		if grantID != "" {
+			r.AddCookie(&http.Cookie{Name: "BFF-Auth", Value: grantID})
+		}
+		return r
+	}
+	alice := &auth.Principal{Subject: "alice", Issuer: "test"}
+	mallory := &auth.Principal{Subject: "mallory", Issuer: "test"}
+
+	var testCases = []struct {
+		description string
+		signer      *session.Signer
+		issuer      *http.Request
+		presenter   *http.Request
+		expectEmpty bool
+		expectValid bool
+	}{
+		{description: "signing disabled", issuer: newRequest(alice, ""), presenter: newRequest(mallory, ""), expectEmpty: true, expectValid: true},
+		{description: "same principal", signer: session.NewSigner(keyring), issuer: newRequest(alice, ""), presenter: newRequest(alice, ""), expectValid: true},
+		{description: "different principal", signer: session.NewSigner(keyring), issuer: newRequest(alice, ""), presenter: newRequest(mallory, "")},
+		{description: "same grant family", signer: session.NewSigner(keyring), issuer: newRequest(nil, grant.ID), presenter: newRequest(nil, grant.ID), expectValid: true},
+		{description: "different grant family", signer: session.NewSigner(keyring), issuer: newRequest(nil, grant.ID), presenter: newRequest(nil, other.ID)},
+	}
+	for _, testCase := range testCases {
+		ids := &SessionIDs{Signer: testCase.signer, Store: store, AuthCookie: "BFF-Auth"}
+		id, err := ids.New(testCase.issuer)
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expectEmpty, id == "", testCase.description)
+		w := httptest.NewRecorder()
+		assert.Equal(t, testCase.expectValid, ids.Verify(w, testCase.presenter, id), testCase.description)
+		if !testCase.expectValid {
+			assert.Equal(t, http.StatusForbidden, w.Code, testCase.description)
+		}
+	}
+}
+
+func TestCSRFCookies(t *testing.T) {
+	assert.Nil(t, CSRFCookies("", ""))
+	assert.Equal(t, []string{"BFF-Auth"}, CSRFCookies("", "BFF-Auth"))
+	assert.Equal(t, []string{"SID", "BFF-Auth"}, CSRFCookies("SID", "BFF-Auth"))
+}
@@ -0,0 +1,254 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrOriginNotAllowed is returned when request Origin is not allowed
+	ErrOriginNotAllowed = errors.New("origin not allowed")
+	// ErrHostNotAllowed is returned when request Host is not allowed
+	ErrHostNotAllowed = errors.New("host not allowed")
+)
+
+// OriginValidator validates request Origin and Host headers to prevent DNS rebinding and cross-site requests.
+//
+// AllowedOrigins entries are origins ("https://app.example.com", "http://localhost:*" for any port) or
+// wildcard subdomains ("https://*.example.com", which does not match the apex); "*" allows any origin.
+// When AllowedOrigins is empty, a browser Origin must match the request Host name and port (same origin); the
+// scheme must be https for TLS requests.
+// AllowedHosts entries are host names ("api.example.com", "*.example.com") optionally with port; when empty any Host is accepted.
+// LocalhostOnly accepts only loopback hosts and origins (localhost, 127.0.0.0/8, ::1).
+// Requests without Origin (non-browser clients) are validated by Host only.
+//
+// Without AllowedHosts or LocalhostOnly the validator only provides cross-site protection: a DNS rebinding attacker
+// controls both Host and Origin, so rebinding protection requires one of them (see HostRestricted).
+type OriginValidator struct {
+	AllowedOrigins []string
+	AllowedHosts   []string
+	LocalhostOnly  bool
+}
+
+// Validate returns an error when request Host or Origin is not allowed
+func (v *OriginValidator) Validate(r *http.Request) error {
+	if v == nil {
+		return nil
+	}
+	if !v.AllowHost(r.Host) {
+		return fmt.Errorf("%w: %v", ErrHostNotAllowed, r.Host)
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if len(v.AllowedOrigins) == 0 && !v.LocalhostOnly {
+		if sameOrigin(r, origin) {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", ErrOriginNotAllowed, origin)
+	}
+	if !v.AllowOrigin(origin) {
+		return fmt.Errorf("%w: %v", ErrOriginNotAllowed, origin)
+	}
+	return nil
+}
+
+// HostRestricted returns true when Host is restricted by AllowedHosts or LocalhostOnly, which DNS rebinding protection requires
+func (v *OriginValidator) HostRestricted() bool {
+	return v != nil && (v.LocalhostOnly || len(v.AllowedHosts) > 0)
+}
+
+// sameOrigin returns true if origin host and port match the request Host; the scheme is checked for TLS requests
+// only, since a TLS-terminating proxy forwards https origins over plain http. A Host without port matches the
+// default port of the origin scheme only.
+func sameOrigin(r *http.Request, origin string) bool {
+	parsed, ok := parseOrigin(origin)
+	if !ok || parsed.host != hostname(r.Host) {
+		return false
+	}
+	if r.TLS != nil && parsed.scheme != "https" {
+		return false
+	}
+	port := portOf(r.Host)
+	if port == "" {
+		port = defaultPort(parsed.scheme)
+	}
+	return port == parsed.port
+}
+
+// AllowHost returns true if Host header value is allowed
+func (v *OriginValidator) AllowHost(host string) bool {
+	if v == nil {
+		return true
+	}
+	name := hostname(host)
+	if v.LocalhostOnly && !isLoopback(name) {
+		return false
+	}
+	if len(v.AllowedHosts) == 0 {
+		return true
+	}
+	port := portOf(host)
+	for _, allowed := range v.AllowedHosts {
+		allowedName, allowedPort := hostname(allowed), portOf(allowed)
+		if allowedPort != "" && allowedPort != port {
+			continue
+		}
+		if matchHost(allowedName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowOrigin returns true if Origin header value matches the allow-list (or is loopback in localhost-only mode)
+func (v *OriginValidator) AllowOrigin(origin string) bool {
+	if v == nil {
+		return true
+	}
+	parsed, ok := parseOrigin(origin)
+	if !ok {
+		return false
+	}
+	if v.LocalhostOnly {
+		return isLoopback(parsed.host)
+	}
+	for _, allowed := range v.AllowedOrigins {
+		if allowed == "*" || MatchOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchOrigin returns true if origin matches pattern ("https://app.example.com", "https://*.example.com", "http://localhost:*")
+func MatchOrigin(pattern, origin string) bool {
+	expect, ok := parseOrigin(pattern)
+	if !ok {
+		return false
+	}
+	actual, ok := parseOrigin(origin)
+	if !ok {
+		return false
+	}
+	if expect.scheme != actual.scheme {
+		return false
+	}
+	if expect.port != "*" && expect.port != actual.port {
+		return false
+	}
+	return matchHost(expect.host, actual.host)
+}
+
+// LocalhostOnly returns validator accepting only loopback hosts and origins
+func LocalhostOnly() *OriginValidator {
+	return &OriginValidator{LocalhostOnly: true}
+}
+
+type origin struct {
+	scheme string
+	host   string
+	port   string
+}
+
+// parseOrigin parses scheme://host[:port] normalizing case and default ports
+func parseOrigin(value string) (*origin, bool) {
+	if value == "" || value == "null" {
+		return nil, false
+	}
+	// url.Parse rejects the port wildcard
+	wildcardPort := strings.HasSuffix(value, ":*")
+	if wildcardPort {
+		value = strings.TrimSuffix(value, ":*")
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.User != nil || (parsed.Path != "" && parsed.Path != "/") {
+		return nil, false
+	}
+	ret := &origin{scheme: strings.ToLower(parsed.Scheme), host: strings.ToLower(parsed.Hostname()), port: parsed.Port()}
+	if wildcardPort {
+		ret.port = "*"
+	} else if ret.port == "" {
+		ret.port = defaultPort(ret.scheme)
+	}
+	return ret, true
+}
+
+// defaultPort returns the default port of http and https schemes
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// matchHost matches host name against pattern; "*.example.com" matches subdomains only
+func matchHost(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return pattern == host
+}
+
+// hostname returns lower-cased host without port (IPv6 brackets removed)
+func hostname(host string) string {
+	host = strings.TrimSpace(host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+func portOf(host string) string {
+	if _, port, err := net.SplitHostPort(strings.TrimSpace(host)); err == nil {
+		return port
+	}
+	return ""
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// SetCORSHeaders sets Access-Control-Allow-Origin (and credentials) for the request origin. The origin is echoed
+// when it is allowed by allowedOrigins or validator; "*" is used only without credentials and with no allow-list configured.
+func SetCORSHeaders(w http.ResponseWriter, r *http.Request, allowCredentials bool, allowedOrigins []string, validator *OriginValidator) {
+	origin := r.Header.Get("Origin")
+	if allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	} else if len(allowedOrigins) == 0 && validator == nil {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Add("Vary", "Origin")
+	if origin == "" {
+		return
+	}
+	allowed := validator != nil && validator.Validate(r) == nil
+	for _, candidate := range allowedOrigins {
+		if allowed {
+			break
+		}
+		allowed = (candidate == "*" && !allowCredentials) || MatchOrigin(candidate, origin)
+	}
+	if allowed {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+}
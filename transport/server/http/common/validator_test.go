@@ -0,0 +1,88 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginValidator_Validate(t *testing.T) {
+	var testCases = []struct {
+		description   string
+		validator     *OriginValidator
+		host          string
+		origin        string
+		forwardedHost string
+		expectErr     error
+	}{
+		{description: "localhost only: local client", validator: LocalhostOnly(), host: "127.0.0.1:8080", origin: "http://localhost:3000"},
+		{description: "localhost only: no origin", validator: LocalhostOnly(), host: "localhost:8080"},
+		{description: "localhost only: ipv6 loopback", validator: LocalhostOnly(), host: "[::1]:8080", origin: "http://[::1]:3000"},
+		{description: "localhost only: rebinding host", validator: LocalhostOnly(), host: "attacker.example:8080", origin: "http://attacker.example:8080", expectErr: ErrHostNotAllowed},
+		{description: "localhost only: rebinding origin", validator: LocalhostOnly(), host: "127.0.0.1:8080", origin: "http://attacker.example", expectErr: ErrOriginNotAllowed},
+		{description: "localhost only: localhost lookalike", validator: LocalhostOnly(), host: "localhost.attacker.example", expectErr: ErrHostNotAllowed},
+		{description: "localhost only: null origin", validator: LocalhostOnly(), host: "localhost", origin: "null", expectErr: ErrOriginNotAllowed},
+		{description: "allow-list: exact", validator: &OriginValidator{AllowedOrigins: []string{"https://app.example.com"}}, host: "api.example.com", origin: "https://app.example.com"},
+		{description: "allow-list: default port", validator: &OriginValidator{AllowedOrigins: []string{"https://app.example.com"}}, host: "api.example.com", origin: "https://app.example.com:443"},
+		{description: "allow-list: scheme mismatch", validator: &OriginValidator{AllowedOrigins: []string{"https://app.example.com"}}, host: "api.example.com", origin: "http://app.example.com", expectErr: ErrOriginNotAllowed},
+		{description: "allow-list: wildcard subdomain", validator: &OriginValidator{AllowedOrigins: []string{"https://*.example.com"}}, host: "api.example.com", origin: "https://a.b.example.com"},
+		{description: "allow-list: wildcard excludes apex", validator: &OriginValidator{AllowedOrigins: []string{"https://*.example.com"}}, host: "api.example.com", origin: "https://example.com", expectErr: ErrOriginNotAllowed},
+		{description: "allow-list: suffix attack", validator: &OriginValidator{AllowedOrigins: []string{"https://*.example.com"}}, host: "api.example.com", origin: "https://evilexample.com", expectErr: ErrOriginNotAllowed},
+		{description: "allow-list: port wildcard", validator: &OriginValidator{AllowedOrigins: []string{"http://localhost:*"}}, host: "localhost:8080", origin: "http://localhost:5173"},
+		{description: "host allow-list: rebinding", validator: &OriginValidator{AllowedHosts: []string{"api.example.com"}}, host: "127.0.0.1:8080", expectErr: ErrHostNotAllowed},
+		{description: "host allow-list: wildcard", validator: &OriginValidator{AllowedHosts: []string{"*.example.com"}}, host: "api.example.com:443"},
+		{description: "host allow-list: port", validator: &OriginValidator{AllowedHosts: []string{"api.example.com:8443"}}, host: "api.example.com:443", expectErr: ErrHostNotAllowed},
+		{description: "same origin default", validator: &OriginValidator{}, host: "api.example.com", origin: "https://api.example.com"},
+		{description: "same origin default: cross site", validator: &OriginValidator{}, host: "127.0.0.1:8080", origin: "http://attacker.example", expectErr: ErrOriginNotAllowed},
+		{description: "same origin default: explicit default port", validator: &OriginValidator{}, host: "api.example.com", origin: "http://api.example.com:80"},
+		{description: "same origin default: host without port, origin on other port", validator: &OriginValidator{}, host: "example.com", origin: "http://example.com:8081", expectErr: ErrOriginNotAllowed},
+		{description: "same origin default: port mismatch", validator: &OriginValidator{}, host: "api.example.com:8443", origin: "https://api.example.com", expectErr: ErrOriginNotAllowed},
+		{description: "same origin default: forwarded host ignored", validator: &OriginValidator{}, host: "127.0.0.1:8080", origin: "http://attacker.example", forwardedHost: "attacker.example", expectErr: ErrOriginNotAllowed},
+		{description: "same origin default: rebinding is cross-site protection only", validator: &OriginValidator{}, host: "attacker.example", origin: "http://attacker.example"},
+		{description: "host allow-list: rebinding with matching origin", validator: &OriginValidator{AllowedHosts: []string{"api.example.com"}}, host: "attacker.example", origin: "http://attacker.example", expectErr: ErrHostNotAllowed},
+		{description: "nil validator", host: "attacker.example", origin: "http://attacker.example"},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "http://"+testCase.host+"/mcp", nil)
+		r.Host = testCase.host
+		if testCase.origin != "" {
+			r.Header.Set("Origin", testCase.origin)
+		}
+		if testCase.forwardedHost != "" {
+			r.Header.Set("X-Forwarded-Host", testCase.forwardedHost)
+		}
+		err := testCase.validator.Validate(r)
+		if testCase.expectErr == nil {
+			assert.Nil(t, err, testCase.description)
+			continue
+		}
+		assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+	}
+}
+
+func TestSetCORSHeaders(t *testing.T) {
+	var testCases = []struct {
+		description      string
+		allowCredentials bool
+		allowedOrigins   []string
+		validator        *OriginValidator
+		origin           string
+		expect           string
+	}{
+		{description: "no configuration", origin: "https://app.example.com", expect: "*"},
+		{description: "allow-list match", allowedOrigins: []string{"https://*.example.com"}, origin: "https://app.example.com", expect: "https://app.example.com"},
+		{description: "allow-list mismatch", allowedOrigins: []string{"https://app.example.com"}, origin: "https://attacker.example"},
+		{description: "validator match", validator: LocalhostOnly(), origin: "http://localhost:3000", expect: "http://localhost:3000"},
+		{description: "credentials exact", allowCredentials: true, allowedOrigins: []string{"https://app.example.com"}, origin: "https://app.example.com", expect: "https://app.example.com"},
+		{description: "credentials never star", allowCredentials: true, allowedOrigins: []string{"*"}, origin: "https://attacker.example"},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodOptions, "http://localhost:8080/mcp", nil)
+		r.Header.Set("Origin", testCase.origin)
+		w := httptest.NewRecorder()
+		SetCORSHeaders(w, r, testCase.allowCredentials, testCase.allowedOrigins, testCase.validator)
+		assert.Equal(t, testCase.expect, w.Header().Get("Access-Control-Allow-Origin"), testCase.description)
+	}
+}
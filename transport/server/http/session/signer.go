@@ -0,0 +1,180 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidID indicates a malformed or tampered session id, or one issued to a different principal
+	ErrInvalidID = errors.New("invalid session id")
+	// ErrExpiredID indicates a session id older than the signer max age
+	ErrExpiredID = errors.New("session id expired")
+	// ErrUnknownKey indicates a session id signed with a key no longer in the keyring
+	ErrUnknownKey = errors.New("session id signed with unknown key")
+)
+
+// Key represents a named HMAC key
+type Key struct {
+	// ID is embedded in signed ids to select the verification key; it must not contain "."
+	ID     string
+	Secret []byte
+}
+
+// Keyring supplies session id keys: the current key signs new ids, any held key verifies them
+type Keyring interface {
+	Current() (*Key, error)
+	Key(id string) (*Key, bool)
+}
+
+// StaticKeyring is an in-memory keyring supporting rotation
+type StaticKeyring struct {
+	keys []*Key
+	mux  sync.RWMutex
+}
+
+// Current returns signing key
+func (k *StaticKeyring) Current() (*Key, error) {
+	k.mux.RLock()
+	defer k.mux.RUnlock()
+	if len(k.keys) == 0 {
+		return nil, fmt.Errorf("session keyring is empty")
+	}
+	return k.keys[0], nil
+}
+
+// Key returns verification key by id
+func (k *StaticKeyring) Key(id string) (*Key, bool) {
+	k.mux.RLock()
+	defer k.mux.RUnlock()
+	for _, candidate := range k.keys {
+		if candidate.ID == id {
+			return candidate, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate makes key current; previous keys still verify until retired
+func (k *StaticKeyring) Rotate(key *Key) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	k.keys = append([]*Key{key}, k.keys...)
+	return nil
+}
+
+// Retire removes key; ids signed with it are rejected with ErrUnknownKey
+func (k *StaticKeyring) Retire(id string) {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+	for i, candidate := range k.keys {
+		if candidate.ID == id {
+			k.keys = append(k.keys[:i:i], k.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func validateKey(key *Key) error {
+	if key == nil || key.ID == "" || strings.Contains(key.ID, ".") {
+		return fmt.Errorf("invalid session key id: must be non empty and must not contain '.'")
+	}
+	if len(key.Secret) < 32 {
+		return fmt.Errorf("session key %v: secret must be at least 32 bytes", key.ID)
+	}
+	return nil
+}
+
+// NewKeyring creates keyring with current key followed by previous keys still accepted for verification
+func NewKeyring(current *Key, previous ...*Key) (*StaticKeyring, error) {
+	ret := &StaticKeyring{}
+	for _, key := range append([]*Key{current}, previous...) {
+		if err := validateKey(key); err != nil {
+			return nil, err
+		}
+		ret.keys = append(ret.keys, key)
+	}
+	return ret, nil
+}
+
+// Signer issues and verifies HMAC-signed session ids of the form "<keyID>.<issuedAt>.<nonce>.<mac>";
+// the mac covers the principal fingerprint, so an id is only valid for the principal it was issued to.
+type Signer struct {
+	keyring Keyring
+	maxAge  time.Duration
+}
+
+// SignerOption represents signer option
+type SignerOption func(s *Signer)
+
+// WithMaxAge rejects ids issued more than maxAge ago (default: no limit, session lifecycle applies)
+func WithMaxAge(maxAge time.Duration) SignerOption {
+	return func(s *Signer) { s.maxAge = maxAge }
+}
+
+// Sign returns new session id bound to fingerprint ("" for anonymous sessions)
+func (s *Signer) Sign(fingerprint string) (string, error) {
+	key, err := s.keyring.Current()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, 16)
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload := key.ID + "." + strconv.FormatInt(time.Now().Unix(), 36) + "." + base64.RawURLEncoding.EncodeToString(nonce)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac(key, payload, fingerprint)), nil
+}
+
+// Verify checks id signature against fingerprint and returns its issuance time
+func (s *Signer) Verify(id, fingerprint string) (time.Time, error) {
+	parts := strings.Split(id, ".")
+	if len(parts) != 4 {
+		return time.Time{}, ErrInvalidID
+	}
+	key, ok := s.keyring.Key(parts[0])
+	if !ok {
+		return time.Time{}, ErrUnknownKey
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || !hmac.Equal(signature, mac(key, strings.Join(parts[:3], "."), fingerprint)) {
+		return time.Time{}, ErrInvalidID
+	}
+	unix, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidID
+	}
+	issuedAt := time.Unix(unix, 0)
+	if s.maxAge > 0 && time.Since(issuedAt) > s.maxAge {
+		return issuedAt, ErrExpiredID
+	}
+	return issuedAt, nil
+}
+
+func mac(key *Key, payload, fingerprint string) []byte {
+	hash := hmac.New(sha256.New, key.Secret)
+	hash.Write([]byte(payload))
+	hash.Write([]byte{0})
+	hash.Write([]byte(fingerprint))
+	return hash.Sum(nil)
+}
+
+// NewSigner creates session id signer
+func NewSigner(keyring Keyring, options ...SignerOption) *Signer {
+	ret := &Signer{keyring: keyring}
+	for _, option := range options {
+		option(ret)
+	}
+	return ret
+}
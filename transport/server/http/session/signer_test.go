@@ -0,0 +1,87 @@
+package session
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	oldKey := &Key{ID: "k1", Secret: bytes.Repeat([]byte("a"), 32)}
+	newKey := &Key{ID: "k2", Secret: bytes.Repeat([]byte("b"), 32)}
+	keyring, err := NewKeyring(oldKey)
+	if !assert.Nil(t, err) {
+		return
+	}
+	signer := NewSigner(keyring)
+	oldID, err := signer.Sign("alice")
+	assert.Nil(t, err)
+	assert.Nil(t, keyring.Rotate(newKey))
+	newID, err := signer.Sign("alice")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(newID, "k2."))
+
+	tamper := func(id string) string {
+		parts := strings.Split(id, ".")
+		parts[2] = "AAAAAAAAAAAAAAAAAAAAAA"
+		return strings.Join(parts, ".")
+	}
+	var testCases = []struct {
+		description string
+		id          string
+		fingerprint string
+		retire      string
+		expectErr   error
+	}{
+		{description: "current key", id: newID, fingerprint: "alice"},
+		{description: "rotated key still verifies", id: oldID, fingerprint: "alice"},
+		{description: "different principal", id: newID, fingerprint: "bob", expectErr: ErrInvalidID},
+		{description: "tampered nonce", id: tamper(newID), fingerprint: "alice", expectErr: ErrInvalidID},
+		{description: "malformed id", id: "5f0c6f4e-uuid", fingerprint: "alice", expectErr: ErrInvalidID},
+		{description: "unknown key", id: "k9" + newID[2:], fingerprint: "alice", expectErr: ErrUnknownKey},
+		{description: "retired key", id: oldID, fingerprint: "alice", retire: "k1", expectErr: ErrUnknownKey},
+	}
+	for _, testCase := range testCases {
+		if testCase.retire != "" {
+			keyring.Retire(testCase.retire)
+		}
+		issuedAt, err := signer.Verify(testCase.id, testCase.fingerprint)
+		if testCase.expectErr != nil {
+			assert.ErrorIs(t, err, testCase.expectErr, testCase.description)
+			continue
+		}
+		assert.Nil(t, err, testCase.description)
+		assert.WithinDuration(t, time.Now(), issuedAt, 2*time.Second, testCase.description)
+	}
+}
+
+func TestSigner_MaxAge(t *testing.T) {
+	keyring, _ := NewKeyring(&Key{ID: "k1", Secret: bytes.Repeat([]byte("a"), 32)})
+	id, err := NewSigner(keyring).Sign("")
+	assert.Nil(t, err)
+	_, err = NewSigner(keyring, WithMaxAge(time.Hour)).Verify(id, "")
+	assert.Nil(t, err)
+	time.Sleep(1100 * time.Millisecond)
+	_, err = NewSigner(keyring, WithMaxAge(time.Millisecond)).Verify(id, "")
+	assert.ErrorIs(t, err, ErrExpiredID)
+}
+
+func TestNewKeyring(t *testing.T) {
+	var testCases = []struct {
+		description string
+		key         *Key
+		expectErr   bool
+	}{
+		{description: "valid key", key: &Key{ID: "k1", Secret: make([]byte, 32)}},
+		{description: "short secret", key: &Key{ID: "k1", Secret: make([]byte, 16)}, expectErr: true},
+		{description: "dot in id", key: &Key{ID: "k.1", Secret: make([]byte, 32)}, expectErr: true},
+		{description: "missing key", expectErr: true},
+	}
+	for _, testCase := range testCases {
+		_, err := NewKeyring(testCase.key)
+		assert.Equal(t, testCase.expectErr, err != nil, testCase.description)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
@@ -14,7 +15,6 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -26,10 +26,29 @@ type Handler struct {
 	locator    session.Locator
 	newHandler transport.NewHandler
 	options    []base.Option
+	budget     *base.EventBudget
+	lifecycle  *base.Lifecycle
+	broker     *base.Broker
+	jobs       *base.Jobs
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (s *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Options.ResourceMetadata.Matches(r) {
+		s.Options.ResourceMetadata.ServeHTTP(w, r)
+		return
+	}
+	if err := s.Options.OriginValidator.Validate(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if r = common.Authenticate(w, r, s.Options.BearerAuth); r == nil {
+		return
+	}
+	if err := s.Options.CSRF.Check(r, s.csrfCookies()...); err != nil {
+		s.Options.CSRF.Reject(w, r, err)
+		return
+	}
 	uri := r.URL.Path
 	if strings.HasSuffix(uri, s.URI) || r.Method == http.MethodGet {
 		s.handleSSE(w, r)
@@ -43,7 +62,14 @@ func (s *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodDelete:
 		if sessionId, _ := s.locator.Locate(s.StreamingSessionLocation, r); sessionId != "" {
-			s.base.Sessions.Delete(sessionId)
+			if !s.sessionIDs().Verify(w, r, sessionId) {
+				return
+			}
+			if aSession, ok := s.base.Sessions.Get(sessionId); ok && !common.BindPrincipal(aSession, r) {
+				common.ErrorPrincipalMismatch(w)
+				return
+			}
+			s.lifecycle.Delete(sessionId)
 			w.WriteHeader(http.StatusOK)
 		}
 
@@ -84,13 +110,14 @@ func (s *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 	}
 	if sessionId == "" && s.Options.RehydrateOnHandshake && s.Options.AuthStore != nil && s.Options.AuthCookie != nil {
 		if authID := s.authCookieValue(r); authID != "" {
-			if g, err := s.Options.AuthStore.Get(r.Context(), authID); err == nil && g != nil {
-				_ = s.Options.AuthStore.Touch(r.Context(), authID, time.Now())
-				if newID, err := s.Options.AuthStore.Rotate(r.Context(), authID, &authpkg.Grant{Subject: g.Subject, Scopes: g.Scopes, UAHash: g.UAHash, IPHint: g.IPHint, FamilyID: g.FamilyID}); err == nil && newID != "" {
-					s.setAuthCookie(w, r, newID)
-				} else {
-					s.setAuthCookie(w, r, authID)
-				}
+			newID, err := common.RehydrateGrant(r, s.Options.AuthStore, authID, s.Options.DeviceBinding)
+			switch {
+			case err == nil:
+				s.setAuthCookie(w, r, newID)
+				r = common.WithRehydratedAuthID(r, newID)
+			case errors.Is(err, authpkg.ErrBindingMismatch), errors.Is(err, authpkg.ErrGrantReused):
+				s.clearAuthCookie(w, r)
+				r = common.WithRehydratedAuthID(r, "")
 			}
 		}
 	}
@@ -101,16 +128,35 @@ func (s *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if sessionId == "" {
-		aSession = base.NewSession(ctx, "", common.NewFlushWriter(w), s.newHandler, s.options...)
+		if sessionId, err = s.sessionIDs().New(r); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create session id: %v", err), http.StatusInternalServerError)
+			return
+		}
+		aSession = base.NewSession(ctx, sessionId, common.NewFlushWriter(w), s.newHandler, s.options...)
 	} else {
+		if !s.sessionIDs().Verify(w, r, sessionId) {
+			return
+		}
 		var ok bool
 		if aSession, ok = s.base.Sessions.Get(sessionId); !ok {
 			http.Error(w, fmt.Sprintf("session '%s' not found", sessionId), http.StatusNotFound)
 			return
 		}
 	}
+	if !common.BindPrincipal(aSession, r) {
+		common.ErrorPrincipalMismatch(w)
+		return
+	}
 	buffer := bytes.Buffer{}
 	ctx = context.WithValue(ctx, jsonrpc.SessionKey, aSession)
+	info := common.NewRequestInfo(r, jsonrpc.TransportSSE, aSession.Id, s.Options.ForwardHeaders)
+	if g := s.authGrant(r); g != nil {
+		info.Grant = g
+	}
+	if principal, ok := authpkg.PrincipalFromContext(r.Context()); ok {
+		info.Principal = principal
+	}
+	ctx = jsonrpc.WithRequestInfo(ctx, info)
 	s.base.HandleMessage(ctx, aSession, data, &buffer)
 
 	if buffer.Len() == 0 { //notification no response
@@ -161,14 +207,34 @@ func (s *Handler) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// authGrant resolves the BFF auth grant referenced by the auth cookie, if configured.
+func (s *Handler) authGrant(r *http.Request) *authpkg.Grant {
+	return common.AuthGrant(r, s.Options.AuthStore, s.authCookieName())
+}
+
 func (s *Handler) authCookieValue(r *http.Request) string {
+	return common.AuthCookieValue(r, s.authCookieName())
+}
+
+func (s *Handler) authCookieName() string {
 	if s.Options.AuthCookie == nil {
 		return ""
 	}
-	if ck, err := r.Cookie(s.Options.AuthCookie.Name); err == nil {
-		return ck.Value
+	return s.Options.AuthCookie.Name
+}
+
+// sessionIDs returns the signer of session ids bound to the request principal or BFF grant
+func (s *Handler) sessionIDs() *common.SessionIDs {
+	return &common.SessionIDs{Signer: s.Options.SessionSigner, Store: s.Options.AuthStore, AuthCookie: s.authCookieName()}
+}
+
+// csrfCookies returns names of cookies that authenticate requests
+func (s *Handler) csrfCookies() []string {
+	var sessionCookie string
+	if s.Options.CookieSession != nil {
+		sessionCookie = s.Options.CookieSession.Name
 	}
-	return ""
+	return common.CSRFCookies(sessionCookie, s.authCookieName())
 }
 
 func (s *Handler) setAuthCookie(w http.ResponseWriter, r *http.Request, id string) {
@@ -189,7 +255,7 @@ func (s *Handler) setAuthCookie(w http.ResponseWriter, r *http.Request, id strin
 		MaxAge:   s.Options.AuthCookie.MaxAge,
 		Secure:   s.Options.AuthCookie.Secure,
 		HttpOnly: s.Options.AuthCookie.HttpOnly,
-		SameSite: s.Options.AuthCookie.SameSite,
+		SameSite: s.Options.CSRF.SameSite(s.Options.AuthCookie.SameSite),
 	}
 	if ck.Path == "" {
 		ck.Path = "/"
@@ -215,7 +281,7 @@ func (s *Handler) clearAuthCookie(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   -1,
 		Secure:   s.Options.AuthCookie.Secure,
 		HttpOnly: s.Options.AuthCookie.HttpOnly,
-		SameSite: s.Options.AuthCookie.SameSite,
+		SameSite: s.Options.CSRF.SameSite(s.Options.AuthCookie.SameSite),
 	}
 	if ck.Path == "" {
 		ck.Path = "/"
@@ -245,27 +311,24 @@ func (s *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 				sid = ck.Value
 			}
 		}
+		if sid != "" && !s.sessionIDs().Verify(w, r, sid) {
+			cancelFun()
+			return
+		}
 		if sid != "" {
 			if aSession, ok := s.base.Sessions.Get(sid); ok {
-				// reattach writer and enable SSE framing/buffer
-				aSession.MarkActiveWithWriter(writer)
+				if !common.BindPrincipal(aSession, r) {
+					cancelFun()
+					common.ErrorPrincipalMismatch(w)
+					return
+				}
+				// enable SSE framing/buffer, then reattach writer
 				base.WithFramer(frameSSE)(aSession)
-				base.WithEventBuffer(s.Options.MaxEventBuffer)(aSession)
-				base.WithEventOverflowPolicy(s.Options.OverflowPolicy)(aSession)
+				s.applyEventBuffer(aSession)
 				base.WithSSE()(aSession)
-
-				// Resumability: replay after Last-Event-ID
-				if last := strings.TrimSpace(r.Header.Get("Last-Event-ID")); last != "" {
-					if v, err := strconv.ParseUint(last, 10, 64); err == nil {
-						if msgs := aSession.EventsAfter(v); len(msgs) > 0 {
-							for _, m := range msgs {
-								if !aSession.WriteBuffered(m) {
-									break
-								}
-							}
-						}
-					}
-				}
+				// Resumability: replay after Last-Event-ID before held messages are released
+				s.base.Events.Publish(common.Reattach(aSession, writer, r.Header.Get("Last-Event-ID")))
+				streamDone := aSession.StreamDone()
 
 				// Optional keepalive with generation guard
 				var stop chan struct{}
@@ -296,13 +359,16 @@ func (s *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 					}(gen)
 				}
 
-				<-r.Context().Done()
+				select {
+				case <-r.Context().Done():
+				case <-streamDone:
+				}
 				if stop != nil {
 					close(stop)
 				}
 				// mark session detached for potential quick reconnect
 				aSession.MarkDetached()
-				aSession.Writer = nil
+				s.base.Events.Emit(base.SessionDetached, aSession)
 				cancelFun()
 				return
 			}
@@ -345,37 +411,61 @@ func (s *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 		}(gen)
 	}
 
-	<-r.Context().Done()
+	select {
+	case <-r.Context().Done():
+	case <-aSession.StreamDone():
+	}
 	if stop != nil {
 		close(stop)
 	}
 	// mark session detached for potential quick reconnect
 	aSession.MarkDetached()
-	aSession.Writer = nil
+	s.base.Events.Emit(base.SessionDetached, aSession)
 	cancelFun()
 }
 
+// Subscribe registers a session lifecycle listener and returns a function removing it.
+func (s *Handler) Subscribe(listener base.SessionListener) func() {
+	return s.base.Events.Subscribe(listener)
+}
+
 // initSessionHandshake initializes a new session.
 func (s *Handler) initSessionHandshake(ctx context.Context, r *http.Request, w http.ResponseWriter, writer *common.FlushWriter) (*base.Session, error) {
-	aSession := base.NewSession(ctx, "", writer, s.newHandler, s.options...)
+	sessionId, err := s.sessionIDs().New(r)
+	if err != nil {
+		return nil, err
+	}
+	aSession := base.NewSession(ctx, sessionId, writer, s.newHandler, s.options...)
 	// enable SSE id injection and buffering for resumability
-	base.WithEventBuffer(s.Options.MaxEventBuffer)(aSession)
-	base.WithEventOverflowPolicy(s.Options.OverflowPolicy)(aSession)
+	s.applyEventBuffer(aSession)
 	base.WithSSE()(aSession)
+	common.BindPrincipal(aSession, r)
 	// do not set transport session cookies; MCP session id is header-only
 	query := url.Values{}
 	if err := s.locator.Set(s.SessionLocation, query, aSession.Id); err != nil {
+		aSession.Release()
 		return nil, err
 	}
 	URI := s.MessageURI + "?" + query.Encode()
 	payload := fmt.Sprintf("event: endpoint\ndata: %s\n\n", URI)
 	if _, err := writer.Write([]byte(payload)); err != nil {
+		aSession.Release()
 		return nil, err
 	}
 	s.base.Sessions.Put(aSession.Id, aSession)
+	s.base.Events.Emit(base.SessionCreated, aSession)
+	s.base.Events.Emit(base.SessionAttached, aSession)
 	return aSession, nil
 }
 
+// applyEventBuffer configures session replay buffer limits.
+func (s *Handler) applyEventBuffer(aSession *base.Session) {
+	base.WithEventOverflowPolicy(s.Options.OverflowPolicy)(aSession)
+	base.WithEventBufferBytes(s.Options.MaxEventBufferBytes)(aSession)
+	base.WithEventBuffer(s.Options.MaxEventBuffer)(aSession)
+	base.WithEventBudget(s.budget)(aSession)
+}
+
 // New creates a new Handler instance with the provided options.
 func New(newHandler transport.NewHandler, options ...Option) *Handler {
 	ret := &Handler{
@@ -386,13 +476,14 @@ func New(newHandler transport.NewHandler, options ...Option) *Handler {
 			SessionLocation:          session.NewQueryLocation("session_id"),
 			StreamingSessionLocation: session.NewQueryLocation("Mcp-Session-Id"),
 			// Lifecycle defaults
-			ReconnectGrace:    30 * time.Second,
-			IdleTTL:           5 * time.Minute,
-			MaxLifetime:       1 * time.Hour,
-			CleanupInterval:   30 * time.Second,
-			MaxEventBuffer:    1024,
-			RemovalPolicy:     base.RemovalAfterGrace,
-			KeepAliveInterval: 30 * time.Second,
+			ReconnectGrace:      30 * time.Second,
+			IdleTTL:             5 * time.Minute,
+			MaxLifetime:         1 * time.Hour,
+			CleanupInterval:     30 * time.Second,
+			MaxEventBuffer:      1024,
+			RemovalPolicy:       base.RemovalAfterGrace,
+			KeepAliveInterval:   30 * time.Second,
+			DetachedSendTimeout: 30 * time.Second,
 		},
 		base: base.NewHandler(),
 		options: []base.Option{
@@ -402,14 +493,43 @@ func New(newHandler transport.NewHandler, options ...Option) *Handler {
 	for _, opt := range options {
 		opt(&ret.Options) // Apply each option to the transport instance
 	}
+	if ret.Options.ResourceMetadata != nil {
+		if err := ret.Options.ResourceMetadata.Validate(); err != nil {
+			ret.base.Logger.Errorf("resource metadata is not served: %v", err)
+		}
+		// the authenticator may be shared across handlers, so metadata is set on a copy
+		if ret.Options.BearerAuth != nil && ret.Options.BearerAuth.Metadata() == nil {
+			ret.Options.BearerAuth = ret.Options.BearerAuth.With(authpkg.WithResourceMetadata(ret.Options.ResourceMetadata))
+		}
+	}
+	if ret.Options.CookieSession != nil {
+		if err := ret.Options.CSRF.ValidateSameSite(ret.Options.CookieSession.Name, ret.Options.CookieSession.SameSite); err != nil {
+			ret.base.Logger.Errorf("csrf: %v", err)
+		}
+	}
+	for _, listener := range ret.Options.SessionListeners {
+		ret.base.Events.Subscribe(listener)
+	}
+	if ret.Options.EventMemoryBudget > 0 {
+		ret.budget = base.NewEventBudget(ret.Options.EventMemoryBudget)
+	}
+	if ret.Options.OutboundQueue != nil {
+		ret.options = append(ret.options, base.WithOutboundQueue(*ret.Options.OutboundQueue))
+	}
+	ret.options = append(ret.options, base.WithDetachedSendTimeout(ret.Options.DetachedSendTimeout), base.WithSessionEvents(ret.base.Events))
+	if ret.Options.TripTimeout > 0 {
+		ret.options = append(ret.options, base.WithTripTimeout(ret.Options.TripTimeout))
+	}
 	// allow custom session store injection
 	if ret.Options.Store != nil {
 		ret.base.Sessions = ret.Options.Store
 	}
+	ret.lifecycle = base.NewLifecycle(ret.base, ret.lifecycleOptions())
+	ret.broker = base.NewBroker(ret.base, ret.Options.BrokerOptions...)
+	ret.jobs = base.NewJobs(ret.base, ret.Options.JobOptions...)
+	ret.base.Authorizer = ret.Options.Authorizer
 	// start cleanup sweeper if configured
-	if ret.Options.CleanupInterval > 0 {
-		go ret.runSweeper()
-	}
+	ret.lifecycle.Start()
 	return ret
 }
 
@@ -427,71 +547,46 @@ func (s *Handler) handleOPTIONS(w http.ResponseWriter, r *http.Request) {
 
 // setCORSHeaders sets Access-Control headers depending on options and request origin.
 func (s *Handler) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
-	origin := r.Header.Get("Origin")
-	if s.Options.AllowCredentials {
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		for _, allowed := range s.Options.AllowedOrigins {
-			if allowed == origin {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				return
-			}
-		}
-		return
-	}
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	common.SetCORSHeaders(w, r, s.Options.AllowCredentials, s.Options.AllowedOrigins, s.Options.OriginValidator)
 }
 
-// runSweeper periodically removes sessions based on lifecycle options.
-func (s *Handler) runSweeper() {
-	ticker := time.NewTicker(s.Options.CleanupInterval)
-	defer ticker.Stop()
-	for range ticker.C {
-		now := time.Now()
-		var toDelete []string
-		s.base.Sessions.Range(func(id string, sess *base.Session) bool {
-			remove := false
-			// Max lifetime
-			if s.Options.MaxLifetime > 0 && now.Sub(sess.CreatedAt) > s.Options.MaxLifetime {
-				remove = true
-			}
-			// Idle TTL
-			if !remove && s.Options.IdleTTL > 0 && now.Sub(sess.LastSeen) > s.Options.IdleTTL {
-				remove = true
-			}
-			// Policy-based detach handling
-			if !remove {
-				switch s.Options.RemovalPolicy {
-				case base.RemovalOnDisconnect:
-					if sess.State == base.SessionStateDetached {
-						remove = true
-					}
-				case base.RemovalAfterGrace:
-					if sess.State == base.SessionStateDetached && s.Options.ReconnectGrace > 0 && sess.DetachedAt != nil {
-						if now.Sub(*sess.DetachedAt) > s.Options.ReconnectGrace {
-							remove = true
-						}
-					}
-				case base.RemovalAfterIdle:
-					// already covered by IdleTTL; nothing extra
-				case base.RemovalManual:
-					// do nothing here
-				}
-			}
-			if remove {
-				toDelete = append(toDelete, id)
-			}
-			return true
-		})
-		for _, id := range toDelete {
-			if s.Options.OnSessionClose != nil {
-				if sess, ok := s.base.Sessions.Get(id); ok {
-					func() {
-						defer func() { _ = recover() }()
-						s.Options.OnSessionClose(sess)
-					}()
-				}
-			}
-			s.base.Sessions.Delete(id)
-		}
+// lifecycleOptions returns session lifecycle settings.
+func (s *Handler) lifecycleOptions() base.LifecycleOptions {
+	ret := base.LifecycleOptions{
+		ReconnectGrace:  s.Options.ReconnectGrace,
+		IdleTTL:         s.Options.IdleTTL,
+		MaxLifetime:     s.Options.MaxLifetime,
+		CleanupInterval: s.Options.CleanupInterval,
+		RemovalPolicy:   s.Options.RemovalPolicy,
+		Policy:          s.Options.ExpiryPolicy,
+	}
+	if s.Options.OnSessionClose != nil {
+		ret.OnClose = append(ret.OnClose, s.Options.OnSessionClose)
 	}
+	return ret
+}
+
+// Sessions returns the session store.
+func (s *Handler) Sessions() base.SessionStore {
+	return s.base.Sessions
+}
+
+// Lifecycle returns the session lifecycle manager.
+func (s *Handler) Lifecycle() *base.Lifecycle {
+	return s.lifecycle
+}
+
+// Broker returns the session publish/subscribe broker.
+func (s *Handler) Broker() *base.Broker {
+	return s.broker
+}
+
+// Jobs returns the asynchronous job runner.
+func (s *Handler) Jobs() *base.Jobs {
+	return s.jobs
+}
+
+// Stop stops the session cleanup sweeper.
+func (s *Handler) Stop() {
+	s.lifecycle.Stop()
 }
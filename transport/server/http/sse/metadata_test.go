@@ -0,0 +1,60 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+type whoamiHandler struct{}
+
+func (h *whoamiHandler) Serve(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	response.Result, _ = json.Marshal(map[string]string{"subject": principal.Subject})
+}
+
+func (h *whoamiHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestHandler_ProtectedResourceMetadata(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		return &auth.Principal{Subject: "alice", Audience: []string{token}}, nil
+	})
+	metadata := &auth.ProtectedResourceMetadata{Resource: "https://api.example.com/sse", AuthorizationServers: []string{"https://issuer.example"}}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+		WithProtectedResourceMetadata(metadata),
+	)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/oauth-protected-resource/sse", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "metadata is served without a token")
+	assert.Contains(t, w.Body.String(), `"authorization_servers":["https://issuer.example"]`)
+
+	post := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	w = post("")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="mcp", resource_metadata="https://api.example.com/.well-known/oauth-protected-resource/sse"`, w.Header().Get("WWW-Authenticate"))
+	w = post("https://other.example.com")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "token issued for another resource")
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	w = post("https://api.example.com/sse")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"subject":"alice"`)
+}
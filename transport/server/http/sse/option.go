@@ -3,6 +3,7 @@ package sse
 import (
 	"github.com/viant/jsonrpc/transport/server/auth"
 	"github.com/viant/jsonrpc/transport/server/base"
+	"github.com/viant/jsonrpc/transport/server/http/common"
 	"github.com/viant/jsonrpc/transport/server/http/session"
 	"time"
 )
@@ -58,6 +59,13 @@ func WithCleanupInterval(d time.Duration) Option { return func(t *Options) { t.C
 // WithMaxEventBuffer sets the default event buffer size used for resumability.
 func WithMaxEventBuffer(n int) Option { return func(t *Options) { t.MaxEventBuffer = n } }
 
+// WithMaxEventBufferBytes bounds per-session replay buffer by total size in bytes.
+func WithMaxEventBufferBytes(n int) Option { return func(t *Options) { t.MaxEventBufferBytes = n } }
+
+// WithEventMemoryBudget bounds replay buffer memory across all sessions; buffers of the oldest
+// detached sessions are evicted first when the budget is exceeded.
+func WithEventMemoryBudget(n int64) Option { return func(t *Options) { t.EventMemoryBudget = n } }
+
 // WithOnSessionClose registers a hook invoked when a session is finally closed.
 func WithOnSessionClose(fn func(*base.Session)) Option {
 	return func(t *Options) { t.OnSessionClose = fn }
@@ -66,6 +74,11 @@ func WithOnSessionClose(fn func(*base.Session)) Option {
 // WithRemovalPolicy sets the session removal policy.
 func WithRemovalPolicy(p base.RemovalPolicy) Option { return func(t *Options) { t.RemovalPolicy = p } }
 
+// WithExpiryPolicy sets a custom function deciding when sessions expire; it replaces the built-in rules.
+func WithExpiryPolicy(policy base.ExpiryPolicy) Option {
+	return func(t *Options) { t.ExpiryPolicy = policy }
+}
+
 // WithOverflowPolicy sets the event buffer overflow policy.
 func WithOverflowPolicy(p base.OverflowPolicy) Option {
 	return func(t *Options) { t.OverflowPolicy = p }
@@ -82,6 +95,11 @@ func WithCORSAllowedOrigins(origins []string) Option {
 	return func(t *Options) { t.AllowedOrigins = origins }
 }
 
+// WithOriginValidator rejects requests whose Origin or Host is not allowed with 403 (e.g. common.LocalhostOnly()).
+func WithOriginValidator(validator *common.OriginValidator) Option {
+	return func(t *Options) { t.OriginValidator = validator }
+}
+
 // WithCORSAllowCredentials toggles Access-Control-Allow-Credentials and credentialed requests.
 func WithCORSAllowCredentials(v bool) Option { return func(t *Options) { t.AllowCredentials = v } }
 
@@ -91,6 +109,36 @@ func WithBFFCookieUseTopDomain(v bool) Option { return func(t *Options) { t.Cook
 // WithAuthStore configures the durable store for BFF auth grants.
 func WithAuthStore(store auth.Store) Option { return func(t *Options) { t.AuthStore = store } }
 
+// WithDeviceBinding enforces grant device hints (User-Agent hash, client IP prefix) when rehydrating from the auth cookie;
+// in strict mode a mismatch revokes the grant family and clears the cookie.
+func WithDeviceBinding(binding *auth.DeviceBinding) Option {
+	return func(t *Options) { t.DeviceBinding = binding }
+}
+
+// WithCSRFPolicy enables CSRF checks for unsafe requests carrying the session or auth cookie;
+// the auth cookie SameSite attribute is raised to policy.MinSameSite.
+func WithCSRFPolicy(policy *common.CSRFPolicy) Option {
+	return func(t *Options) { t.CSRF = policy }
+}
+
+// WithSessionSigner issues signed session ids bound to the bearer principal or BFF grant family; ids that are
+// tampered with, signed with a retired key or presented by a different principal are rejected with 403.
+func WithSessionSigner(signer *session.Signer) Option {
+	return func(t *Options) { t.SessionSigner = signer }
+}
+
+// WithBearerAuth requires a valid bearer token on every request; sessions are bound to the token principal.
+func WithBearerAuth(authenticator *auth.BearerAuthenticator) Option {
+	return func(t *Options) { t.BearerAuth = authenticator }
+}
+
+// WithProtectedResourceMetadata serves RFC 9728 metadata at /.well-known/oauth-protected-resource,
+// advertises it via resource_metadata in bearer challenges and requires tokens issued for metadata.Resource.
+// metadata.Resource must be an absolute https URL (see auth.NewProtectedResourceMetadata); invalid metadata is logged and never served.
+func WithProtectedResourceMetadata(metadata *auth.ProtectedResourceMetadata) Option {
+	return func(t *Options) { t.ResourceMetadata = metadata }
+}
+
 // WithBFFAuthCookie configures the cookie used to carry the BFF auth grant id.
 func WithBFFAuthCookie(c *BFFAuthCookie) Option { return func(t *Options) { t.AuthCookie = c } }
 
@@ -110,3 +158,47 @@ func WithLogoutAllPath(path string) Option { return func(t *Options) { t.LogoutA
 func WithKeepAliveInterval(d time.Duration) Option {
 	return func(t *Options) { t.KeepAliveInterval = d }
 }
+
+// WithForwardedHeaders sets the allow-list of HTTP headers exposed to handlers via jsonrpc.RequestInfo.
+func WithForwardedHeaders(names ...string) Option {
+	return func(t *Options) { t.ForwardHeaders = append(t.ForwardHeaders, names...) }
+}
+
+// WithOutboundQueue enables a bounded asynchronous per-session outbound queue with the given depth, byte limit and full policy.
+func WithOutboundQueue(options base.QueueOptions) Option {
+	return func(t *Options) { t.OutboundQueue = &options }
+}
+
+// WithDetachedSendTimeout sets how long server-initiated requests and notifications are held
+// while the client stream is detached. Set to 0 to fail immediately with base.ErrClientNotConnected.
+func WithDetachedSendTimeout(d time.Duration) Option {
+	return func(t *Options) { t.DetachedSendTimeout = d }
+}
+
+// WithTripTimeout sets how long server-initiated requests (e.g. sampling, elicitation) wait for the client response.
+// Use transport.WithTripTimeout(ctx, d) to override it for a single call.
+func WithTripTimeout(d time.Duration) Option {
+	return func(t *Options) { t.TripTimeout = d }
+}
+
+// WithSessionListener registers a listener receiving session lifecycle events
+// (created, attached, detached, reattached, overflowed, expired, deleted).
+func WithSessionListener(listener base.SessionListener) Option {
+	return func(t *Options) { t.SessionListeners = append(t.SessionListeners, listener) }
+}
+
+// WithBrokerOptions configures the publish/subscribe broker returned by Handler.Broker.
+func WithBrokerOptions(options ...base.BrokerOption) Option {
+	return func(t *Options) { t.BrokerOptions = append(t.BrokerOptions, options...) }
+}
+
+// WithJobOptions configures the asynchronous job runner returned by Handler.Jobs (e.g. job store).
+func WithJobOptions(options ...base.JobOption) Option {
+	return func(t *Options) { t.JobOptions = append(t.JobOptions, options...) }
+}
+
+// WithAuthorizer rejects requests not allowed by authorizer (e.g. auth.NewMethodAuthorizer) with a JSON-RPC error
+// before they reach the handler; the authorizer sees the bearer principal and BFF grant of the request.
+func WithAuthorizer(authorizer base.Authorizer) Option {
+	return func(t *Options) { t.Authorizer = authorizer }
+}
@@ -3,6 +3,7 @@ package sse
 import (
 	"github.com/viant/jsonrpc/transport/server/auth"
 	"github.com/viant/jsonrpc/transport/server/base"
+	"github.com/viant/jsonrpc/transport/server/http/common"
 	"github.com/viant/jsonrpc/transport/server/http/session"
 	"net/http"
 	"time"
@@ -21,9 +22,15 @@ type Options struct {
 	MaxLifetime     time.Duration
 	CleanupInterval time.Duration
 	MaxEventBuffer  int
-	OnSessionClose  func(*base.Session)
-	RemovalPolicy   base.RemovalPolicy
-	OverflowPolicy  base.OverflowPolicy
+	// MaxEventBufferBytes bounds per-session replay buffer by total bytes (zero means unbounded).
+	MaxEventBufferBytes int
+	// EventMemoryBudget bounds replay buffer memory across all sessions (zero means unbounded).
+	EventMemoryBudget int64
+	OnSessionClose    func(*base.Session)
+	RemovalPolicy     base.RemovalPolicy
+	// ExpiryPolicy replaces built-in expiry rules (MaxLifetime, IdleTTL, RemovalPolicy) when set.
+	ExpiryPolicy   base.ExpiryPolicy
+	OverflowPolicy base.OverflowPolicy
 	// Optional custom session store (e.g., Redis-backed). Defaults to in-memory.
 	Store base.SessionStore
 
@@ -32,6 +39,8 @@ type Options struct {
 	// CORS settings for browsers
 	AllowedOrigins   []string
 	AllowCredentials bool
+	// OriginValidator rejects requests with disallowed Origin or Host (DNS rebinding protection) before any session is created.
+	OriginValidator *common.OriginValidator
 
 	// If true and CookieSession.Domain is empty, set cookie Domain to the request's top domain (eTLD+1).
 	CookieUseTopDomain bool
@@ -42,10 +51,46 @@ type Options struct {
 	AuthCookieUseTopDomain bool
 	RehydrateOnHandshake   bool
 	LogoutAllPath          string
+	// DeviceBinding checks grant User-Agent and IP hints on rehydrate (strict, warn or off).
+	DeviceBinding *auth.DeviceBinding
+	// CSRF protects cookie-authenticated unsafe requests against cross-site request forgery.
+	CSRF *common.CSRFPolicy
+	// SessionSigner issues HMAC-signed session ids bound to the request principal or BFF grant (random UUIDs when nil).
+	SessionSigner *session.Signer
+
+	// BearerAuth authenticates "Authorization: Bearer" requests; the principal is bound to the session.
+	BearerAuth *auth.BearerAuthenticator
+	// ResourceMetadata is served at the RFC 9728 well-known path and advertised in bearer challenges.
+	ResourceMetadata *auth.ProtectedResourceMetadata
 
 	// KeepAliveInterval controls emission of SSE keepalive frames on the
 	// long-lived GET stream. Zero or negative disables keepalives.
 	KeepAliveInterval time.Duration
+
+	// ForwardHeaders lists HTTP headers exposed to handlers via jsonrpc.RequestInfo.
+	ForwardHeaders []string
+
+	// OutboundQueue enables asynchronous per-session outbound queue (disabled when nil).
+	OutboundQueue *base.QueueOptions
+
+	// DetachedSendTimeout bounds how long server-initiated messages wait for a client stream
+	// to (re)attach before failing with base.ErrClientNotConnected. Zero fails immediately.
+	DetachedSendTimeout time.Duration
+
+	// TripTimeout bounds how long server-initiated requests wait for the client response (default 5m).
+	TripTimeout time.Duration
+
+	// SessionListeners receive session lifecycle events.
+	SessionListeners []base.SessionListener
+
+	// BrokerOptions configure the publish/subscribe broker (e.g. subscription method names).
+	BrokerOptions []base.BrokerOption
+
+	// JobOptions configure the asynchronous job runner (e.g. job store, retention).
+	JobOptions []base.JobOption
+
+	// Authorizer rejects requests with a JSON-RPC error before they reach the handler (e.g. auth.NewMethodAuthorizer).
+	Authorizer base.Authorizer
 }
 
 // BFFCookie defines cookie attributes used to carry the session id.
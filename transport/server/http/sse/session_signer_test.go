@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/http/session"
+)
+
+func TestHandler_SessionSigner(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		return &auth.Principal{Subject: token, Issuer: "test"}, nil
+	})
+	keyring, err := session.NewKeyring(&session.Key{ID: "k1", Secret: bytes.Repeat([]byte("k"), 32)})
+	if !assert.Nil(t, err) {
+		return
+	}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+		WithSessionSigner(session.NewSigner(keyring)),
+	)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/sse", nil)
+	req.Header.Set("Authorization", "Bearer alice")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	var endpoint string
+	reader := bufio.NewReader(resp.Body)
+	for endpoint == "" {
+		line, err := reader.ReadString('\n')
+		if !assert.Nil(t, err) {
+			return
+		}
+		endpoint = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if !strings.HasPrefix(line, "data:") {
+			endpoint = ""
+		}
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sessionID := endpointURL.Query().Get("session_id")
+	if !assert.True(t, strings.HasPrefix(sessionID, "k1."), sessionID) {
+		return
+	}
+
+	var testCases = []struct {
+		description string
+		token       string
+		sessionID   string
+		expectCode  int
+	}{
+		{description: "session owner", token: "alice", sessionID: sessionID, expectCode: http.StatusAccepted},
+		{description: "other principal", token: "bob", sessionID: sessionID, expectCode: http.StatusForbidden},
+		{description: "tampered id", token: "alice", sessionID: sessionID + "x", expectCode: http.StatusForbidden},
+		{description: "unsigned id", token: "alice", sessionID: "plain-id", expectCode: http.StatusForbidden},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/message?session_id="+url.QueryEscape(testCase.sessionID), strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		r.Header.Set("Authorization", "Bearer "+testCase.token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, testCase.expectCode, w.Code, testCase.description)
+	}
+}
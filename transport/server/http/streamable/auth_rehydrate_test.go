@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
 	"github.com/viant/jsonrpc/transport/server/auth"
@@ -48,3 +50,55 @@ func TestRehydrateOnHandshake_WithAuthCookie(t *testing.T) {
 		t.Fatalf("expected %s header to be set", defaultSessionHeaderKey)
 	}
 }
+
+func TestRehydrateOnHandshake_GrantReuse(t *testing.T) {
+	ctx := context.Background()
+	store := auth.NewMemoryStore(time.Hour, 24*time.Hour, 0)
+	var events []*auth.SecurityEvent
+	store.SetSecurityHook(func(ctx context.Context, event *auth.SecurityEvent) { events = append(events, event) })
+	g := auth.NewGrant("user-123")
+	assert.Nil(t, store.Put(ctx, g))
+
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &noopSrv{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithAuthStore(store),
+		WithBFFAuthCookie(&BFFAuthCookie{Name: "BFF-Auth-Session", HttpOnly: true}),
+		WithRehydrateOnHandshake(true),
+	)
+	handshake := func(authID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+		r.AddCookie(&http.Cookie{Name: "BFF-Auth-Session", Value: authID})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	cookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, candidate := range w.Result().Cookies() {
+			if candidate.Name == "BFF-Auth-Session" {
+				return candidate
+			}
+		}
+		return nil
+	}
+
+	w := handshake(g.ID)
+	rotated := cookie(w)
+	if !assert.NotNil(t, rotated) {
+		return
+	}
+	assert.NotEqual(t, g.ID, rotated.Value)
+	assert.Empty(t, events, "the handshake resolves the rotated grant, not the cookie it replaced")
+	_, err := store.Get(ctx, rotated.Value)
+	assert.Nil(t, err)
+
+	w = handshake(g.ID) // replayed stolen cookie
+	if cleared := cookie(w); assert.NotNil(t, cleared) {
+		assert.True(t, cleared.MaxAge < 0, "auth cookie is cleared")
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, auth.GrantReused, events[0].Type)
+	}
+	_, err = store.Get(ctx, rotated.Value)
+	assert.ErrorIs(t, err, auth.ErrNotFound, "family revoked")
+}
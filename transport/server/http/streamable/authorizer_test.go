@@ -0,0 +1,40 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+func TestStreamable_Authorizer(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		return &auth.Principal{Subject: "alice", Scopes: strings.Fields(token)}, nil
+	})
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+		WithAuthorizer(auth.NewMethodAuthorizer(auth.WithMethodScopes("whoami", "profile"))),
+	)
+	post := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	w := post("email")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":-32003`)
+	assert.NotContains(t, w.Body.String(), `"subject"`)
+
+	w = post("email profile")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"subject":"alice"`)
+}
@@ -0,0 +1,70 @@
+package streamable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+type whoamiHandler struct{}
+
+func (h *whoamiHandler) Serve(ctx context.Context, _ *jsonrpc.Request, response *jsonrpc.Response) {
+	principal, _ := auth.PrincipalFromContext(ctx)
+	response.Result, _ = json.Marshal(map[string]string{"subject": principal.Subject})
+}
+
+func (h *whoamiHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestStreamable_BearerAuth(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		switch token {
+		case "alice-1", "alice-2":
+			return &auth.Principal{Subject: "alice", Issuer: "test"}, nil
+		case "bob":
+			return &auth.Principal{Subject: "bob", Issuer: "test"}, nil
+		}
+		return nil, auth.ErrInvalidToken
+	})
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+	)
+	post := func(token, sessionID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if sessionID != "" {
+			r.Header.Set(defaultSessionHeaderKey, sessionID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post("", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="mcp"`, w.Header().Get("WWW-Authenticate"))
+	w = post("forged", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	w = post("alice-1", "")
+	if !assert.Equal(t, http.StatusOK, w.Code) {
+		return
+	}
+	assert.Contains(t, w.Body.String(), `"subject":"alice"`)
+	sessionID := w.Header().Get(defaultSessionHeaderKey)
+
+	assert.Equal(t, http.StatusOK, post("alice-2", sessionID).Code, "refreshed token of the same principal")
+	assert.Equal(t, http.StatusForbidden, post("bob", sessionID).Code, "session bound to another principal")
+}
@@ -0,0 +1,68 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/http/common"
+)
+
+func TestHandler_CSRF(t *testing.T) {
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &noopSrv{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithBFFCookieSession(&BFFCookie{Name: "BFF-Session"}),
+		WithCSRFPolicy(&common.CSRFPolicy{DoubleSubmit: true, RejectSimpleRequests: true, RequiredHeaders: []string{"X-Requested-With"}}),
+	)
+	var testCases = []struct {
+		description string
+		contentType string
+		cookies     map[string]string
+		headers     map[string]string
+		expectCode  int
+		expectToken bool
+	}{
+		{description: "bearer style request without cookies", contentType: "application/json", expectCode: http.StatusOK},
+		{description: "cross-site text/plain post", contentType: "text/plain", cookies: map[string]string{"BFF-Session": "x"}, expectCode: http.StatusForbidden},
+		{description: "missing custom header", contentType: "application/json", cookies: map[string]string{"BFF-Session": "x"}, expectCode: http.StatusForbidden},
+		{description: "missing token issues one", contentType: "application/json", cookies: map[string]string{"BFF-Session": "x"}, headers: map[string]string{"X-Requested-With": "fetch"}, expectCode: http.StatusForbidden, expectToken: true},
+		{description: "valid double submit", contentType: "application/json", cookies: map[string]string{"BFF-Session": "x", "XSRF-TOKEN": "t"}, headers: map[string]string{"X-Requested-With": "fetch", "X-XSRF-Token": "t"}, expectCode: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+		r.Header.Set("Content-Type", testCase.contentType)
+		for name, value := range testCase.cookies {
+			r.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		for name, value := range testCase.headers {
+			r.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, testCase.expectCode, w.Code, testCase.description)
+		issued := false
+		for _, cookie := range w.Result().Cookies() {
+			issued = issued || (cookie.Name == "XSRF-TOKEN" && cookie.Value != "")
+		}
+		assert.Equal(t, testCase.expectToken, issued, testCase.description)
+	}
+}
+
+func TestHandler_CSRFAuthCookieSameSite(t *testing.T) {
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &noopSrv{} },
+		WithCleanupInterval(0),
+		WithBFFAuthCookie(&BFFAuthCookie{Name: "BFF-Auth-Session", HttpOnly: true, SameSite: http.SameSiteNoneMode}),
+		WithCSRFPolicy(&common.CSRFPolicy{MinSameSite: http.SameSiteLaxMode}),
+	)
+	w := httptest.NewRecorder()
+	h.setAuthCookie(w, httptest.NewRequest(http.MethodPost, "/mcp", nil), "grant")
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+}
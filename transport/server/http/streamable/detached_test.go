@@ -0,0 +1,111 @@
+package streamable
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+// notifyingHandler sends a server notification while serving each request
+type notifyingHandler struct {
+	transport transport.Transport
+}
+
+func (h *notifyingHandler) Serve(ctx context.Context, _ *jsonrpc.Request, resp *jsonrpc.Response) {
+	notification, _ := jsonrpc.NewNotification("notifications/progress", map[string]int{"progress": 1})
+	_ = h.transport.Notify(ctx, notification)
+	resp.Result = []byte(`{"ok":true}`)
+}
+
+func (h *notifyingHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestStreamable_NotifyWithoutStream(t *testing.T) {
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler {
+		return &notifyingHandler{transport: tr}
+	}, WithURI("/mcp"), WithCleanupInterval(0), WithDetachedSendTimeout(30*time.Second))
+	post := func(sessionID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`))
+		if sessionID != "" {
+			r.Header.Set(defaultSessionHeaderKey, sessionID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	sessionID := post("").Header().Get(defaultSessionHeaderKey)
+	started := time.Now()
+	w := post(sessionID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ok":true`)
+	assert.Less(t, time.Since(started), time.Second, "notification must not wait for an optional GET stream")
+}
+
+func TestStreamable_ReconnectReplaysBeforeHeld(t *testing.T) {
+	trs := make(chan transport.Transport, 1)
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler {
+		trs <- tr
+		return &serverHandler{}
+	}, WithURI("/mcp"), WithCleanupInterval(0), WithKeepAliveInterval(0), WithDetachedSendTimeout(5*time.Second))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/mcp", "application/json", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get(defaultSessionHeaderKey)
+	tr := <-trs
+	aSession, _ := h.base.Sessions.Get(sessionID)
+	stream := func(lastEventID string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/mcp", nil)
+		req.Header.Set("Accept", sseMime)
+		req.Header.Set(defaultSessionHeaderKey, sessionID)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err) {
+			t.FailNow()
+		}
+		return resp
+	}
+	notify := func(method string) error {
+		notification, _ := jsonrpc.NewNotification(method, nil)
+		return tr.Notify(context.Background(), notification)
+	}
+
+	first := stream("")
+	assert.Eventually(t, aSession.StreamAttached, time.Second, 10*time.Millisecond)
+	assert.Nil(t, notify("notifications/first"))
+	assert.Nil(t, notify("notifications/missed"))
+	_ = first.Body.Close()
+	assert.Eventually(t, func() bool { return !aSession.StreamAttached() }, time.Second, 10*time.Millisecond)
+
+	held := make(chan error, 1)
+	go func() { held <- notify("notifications/held") }()
+	time.Sleep(50 * time.Millisecond)
+	second := stream("1")
+	defer second.Body.Close()
+	var methods []string
+	scanner := bufio.NewScanner(second.Body)
+	for scanner.Scan() && len(methods) < 2 {
+		if line := scanner.Text(); strings.HasPrefix(line, "data:") {
+			notification := &jsonrpc.Notification{}
+			if assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), notification)) {
+				methods = append(methods, notification.Method)
+			}
+		}
+	}
+	assert.Equal(t, []string{"notifications/missed", "notifications/held"}, methods)
+	assert.Nil(t, <-held)
+}
@@ -4,17 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	tbase "github.com/viant/jsonrpc/transport/base"
 	authpkg "github.com/viant/jsonrpc/transport/server/auth"
 	"github.com/viant/jsonrpc/transport/server/base"
+	"github.com/viant/jsonrpc/transport/server/cluster"
 	"github.com/viant/jsonrpc/transport/server/http/common"
 	"github.com/viant/jsonrpc/transport/server/http/session"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -35,6 +38,11 @@ type Handler struct {
 	locator    session.Locator
 	newHandler transport.NewHandler
 	options    []base.Option
+	budget     *base.EventBudget
+	lifecycle  *base.Lifecycle
+	broker     *base.Broker
+	jobs       *base.Jobs
+	node       *cluster.Node
 }
 
 // ServeHTTP implements http.Handler.
@@ -43,10 +51,25 @@ type Handler struct {
 // GET  (with Accept: text/event-stream & Mcp-Session-Id) – opens long-lived streaming connection.
 // DELETE (with Mcp-Session-Id) – terminates session.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Options.ResourceMetadata.Matches(r) {
+		h.Options.ResourceMetadata.ServeHTTP(w, r)
+		return
+	}
 	if h.URI != "" && !strings.HasSuffix(r.URL.Path, h.URI) {
 		http.NotFound(w, r)
 		return
 	}
+	if err := h.Options.OriginValidator.Validate(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if r = common.Authenticate(w, r, h.Options.BearerAuth); r == nil {
+		return
+	}
+	if err := h.Options.CSRF.Check(r, h.csrfCookies()...); err != nil {
+		h.Options.CSRF.Reject(w, r, err)
+		return
+	}
 	if h.Options.LogoutAllPath != "" && strings.HasSuffix(r.URL.Path, h.Options.LogoutAllPath) {
 		h.handleLogoutAll(w, r)
 		return
@@ -73,15 +96,15 @@ func (h *Handler) handlePOST(w http.ResponseWriter, r *http.Request) {
 		// Rehydrate MCP session using BFF auth cookie if configured
 		if h.Options.RehydrateOnHandshake && h.Options.AuthStore != nil && h.Options.AuthCookie != nil {
 			if authID := h.authCookieValue(r); authID != "" {
-				if g, err := h.Options.AuthStore.Get(r.Context(), authID); err == nil && g != nil {
-					// touch and rotate on use
-					_ = h.Options.AuthStore.Touch(r.Context(), authID, time.Now())
-					newID, err := h.Options.AuthStore.Rotate(r.Context(), authID, &authpkg.Grant{Subject: g.Subject, Scopes: g.Scopes, UAHash: g.UAHash, IPHint: g.IPHint, FamilyID: g.FamilyID})
-					if err == nil && newID != "" {
-						h.setAuthCookie(w, r, newID)
-					} else {
-						h.setAuthCookie(w, r, authID)
-					}
+				// check device binding, touch and rotate on use
+				newID, err := common.RehydrateGrant(r, h.Options.AuthStore, authID, h.Options.DeviceBinding)
+				switch {
+				case err == nil:
+					h.setAuthCookie(w, r, newID)
+					r = common.WithRehydratedAuthID(r, newID)
+				case errors.Is(err, authpkg.ErrBindingMismatch), errors.Is(err, authpkg.ErrGrantReused):
+					h.clearAuthCookie(w, r)
+					r = common.WithRehydratedAuthID(r, "")
 				}
 			}
 		}
@@ -114,12 +137,19 @@ func (h *Handler) handleGET(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("missing %s", h.SessionLocation.Name), http.StatusBadRequest)
 		return
 	}
+	if !h.sessionIDs().Verify(w, r, sessionID) {
+		return
+	}
 
 	aSession, ok := h.base.Sessions.Get(sessionID)
 	if !ok {
 		http.Error(w, fmt.Sprintf("session '%s' not found", sessionID), http.StatusNotFound)
 		return
 	}
+	if !common.BindPrincipal(aSession, r) {
+		common.ErrorPrincipalMismatch(w)
+		return
+	}
 
 	// last event id support (reserved; implemented in resumability step)
 	_ = r.Header.Get("Last-Event-ID")
@@ -135,12 +165,13 @@ func (h *Handler) handleGET(w http.ResponseWriter, r *http.Request) {
 		flusher.Flush()
 	}
 
-	// Reattach writer that flushes every message and switch to SSE framing
-	aSession.MarkActiveWithWriter(common.NewFlushWriter(w))
+	// Switch to SSE framing before reattaching so that held messages are framed for the stream
 	base.WithFramer(frameSSE)(aSession)
-	base.WithEventBuffer(h.Options.MaxEventBuffer)(aSession)
-	base.WithEventOverflowPolicy(h.Options.OverflowPolicy)(aSession)
+	h.applyEventBuffer(aSession)
 	base.WithSSE()(aSession)
+	// Replay events after Last-Event-ID before held messages are released, then publish attach event
+	h.base.Events.Publish(common.Reattach(aSession, common.NewFlushWriter(w), r.Header.Get("Last-Event-ID")))
+	streamDone := aSession.StreamDone()
 
 	// Keepalive loop guarded by writer generation
 	if h.Options.KeepAliveInterval > 0 {
@@ -171,22 +202,13 @@ func (h *Handler) handleGET(w http.ResponseWriter, r *http.Request) {
 		defer close(stop)
 	}
 
-	// Support resumability: replay events after Last-Event-ID if provided
-	if last := strings.TrimSpace(r.Header.Get("Last-Event-ID")); last != "" {
-		if v, err := strconv.ParseUint(last, 10, 64); err == nil {
-			if msgs := aSession.EventsAfter(v); len(msgs) > 0 {
-				for _, m := range msgs {
-					if !aSession.WriteBuffered(m) {
-						break
-					}
-				}
-			}
-		}
+	// Block until client closes (or session requests disconnect), then mark session detached for quick reconnect.
+	select {
+	case <-r.Context().Done():
+	case <-streamDone:
 	}
-
-	// Block until client closes, then mark session detached for quick reconnect.
-	<-r.Context().Done()
 	aSession.MarkDetached()
+	h.base.Events.Emit(base.SessionDetached, aSession)
 }
 
 func (h *Handler) handleDELETE(w http.ResponseWriter, r *http.Request) {
@@ -195,10 +217,22 @@ func (h *Handler) handleDELETE(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("missing %s", h.SessionLocation.Name), http.StatusBadRequest)
 		return
 	}
-	h.base.Sessions.Delete(sessionID)
+	if !h.sessionIDs().Verify(w, r, sessionID) {
+		return
+	}
+	if aSession, ok := h.base.Sessions.Get(sessionID); ok && !common.BindPrincipal(aSession, r) {
+		common.ErrorPrincipalMismatch(w)
+		return
+	}
+	h.lifecycle.Delete(sessionID)
 	w.WriteHeader(http.StatusOK)
 }
 
+// Subscribe registers a session lifecycle listener and returns a function removing it.
+func (h *Handler) Subscribe(listener base.SessionListener) func() {
+	return h.base.Events.Subscribe(listener)
+}
+
 // initHandshake creates a new session and returns its id in response header.
 func (h *Handler) initHandshake(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -207,12 +241,26 @@ func (h *Handler) initHandshake(w http.ResponseWriter, r *http.Request) {
 	//if err != nil {
 	//	http.Error(w, err.Error(), http.StatusBadRequest)
 	//}
-	aSession := base.NewSession(ctx, "", io.Discard, h.newHandler)
+	sessionID, err := h.sessionIDs().New(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to create session id: %v", err), http.StatusInternalServerError)
+		return
+	}
+	aSession := base.NewSession(ctx, sessionID, io.Discard, h.newHandler)
 	// apply buffering; framer will be configured when streaming begins
-	base.WithEventBuffer(h.Options.MaxEventBuffer)(aSession)
-	base.WithEventOverflowPolicy(h.Options.OverflowPolicy)(aSession)
+	h.applyEventBuffer(aSession)
+	if h.Options.OutboundQueue != nil {
+		base.WithOutboundQueue(*h.Options.OutboundQueue)(aSession)
+	}
+	base.WithDetachedSendTimeout(h.Options.DetachedSendTimeout)(aSession)
+	if h.Options.TripTimeout > 0 {
+		base.WithTripTimeout(h.Options.TripTimeout)(aSession)
+	}
+	base.WithSessionEvents(h.base.Events)(aSession)
+	common.BindPrincipal(aSession, r)
 
 	h.base.Sessions.Put(aSession.Id, aSession)
+	h.base.Events.Emit(base.SessionCreated, aSession)
 	// return session id at the configured location; for header we always set header
 	// and use the configured header name
 	if h.SessionLocation != nil && h.SessionLocation.Kind == "header" {
@@ -228,11 +276,22 @@ func (h *Handler) initHandshake(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if !h.sessionIDs().Verify(w, r, sessionID) {
+		return
+	}
 	aSession, ok := h.base.Sessions.Get(sessionID)
 	if !ok {
+		if h.node != nil {
+			h.forwardMessage(w, r, sessionID)
+			return
+		}
 		http.Error(w, fmt.Sprintf("session '%s' not found", sessionID), http.StatusNotFound)
 		return
 	}
+	if !common.BindPrincipal(aSession, r) {
+		common.ErrorPrincipalMismatch(w)
+		return
+	}
 
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -242,6 +301,20 @@ func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request, sessionI
 	_ = r.Body.Close()
 
 	ctx := context.WithValue(r.Context(), jsonrpc.SessionKey, aSession)
+	info := common.NewRequestInfo(r, jsonrpc.TransportStreamable, aSession.Id, h.Options.ForwardHeaders)
+	if g := h.authGrant(r); g != nil {
+		info.Grant = g
+	}
+	if principal, ok := authpkg.PrincipalFromContext(r.Context()); ok {
+		info.Principal = principal
+	}
+	ctx = jsonrpc.WithRequestInfo(ctx, info)
+
+	// Requests asking for progress are upgraded to an SSE stream when the client accepts it
+	if acceptsSSE(r.Header) && wantsStream(data) {
+		h.streamMessage(ctx, w, aSession, data)
+		return
+	}
 
 	// Default: synchronous JSON response or 202 Accepted for notifications
 	buffer := bytes.Buffer{}
@@ -257,6 +330,79 @@ func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request, sessionI
 	_, _ = w.Write(buffer.Bytes())
 }
 
+// streamMessage serves request over a POST SSE stream carrying progress and chunk notifications followed by the response.
+func (h *Handler) streamMessage(ctx context.Context, w http.ResponseWriter, aSession *base.Session, data []byte) {
+	w.Header().Set("Content-Type", sseMime)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set(defaultSessionHeaderKey, aSession.Id)
+	w.WriteHeader(http.StatusOK)
+	writer := common.NewFlushWriter(w)
+	var mux sync.Mutex
+	write := func(data []byte) error {
+		mux.Lock()
+		defer mux.Unlock()
+		_, err := writer.Write(frameSSE(data))
+		return err
+	}
+	buffer := bytes.Buffer{}
+	h.base.HandleMessage(base.WithRequestStream(ctx, write), aSession, data, &buffer)
+	if buffer.Len() > 0 {
+		_ = write(buffer.Bytes())
+	}
+}
+
+// wantsStream returns true if data is a request carrying a progress token or asking for a streamed result.
+func wantsStream(data []byte) bool {
+	if tbase.MessageType(data) != jsonrpc.MessageTypeRequest {
+		return false
+	}
+	request := &jsonrpc.Request{}
+	if err := json.Unmarshal(data, request); err != nil {
+		return false
+	}
+	_, ok := transport.ProgressToken(request)
+	return ok || transport.StreamRequested(request)
+}
+
+// forwardMessage forwards message for a session held by another replica and writes its output.
+func (h *Handler) forwardMessage(w http.ResponseWriter, r *http.Request, sessionID string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	_ = r.Body.Close()
+	info := common.NewRequestInfo(r, jsonrpc.TransportStreamable, sessionID, h.Options.ForwardHeaders)
+	if g := h.authGrant(r); g != nil {
+		info.Grant = g
+	}
+	if principal, ok := authpkg.PrincipalFromContext(r.Context()); ok {
+		info.Principal = principal
+	}
+	output, err := h.node.Forward(r.Context(), sessionID, data, info)
+	if err != nil {
+		if errors.Is(err, cluster.ErrSessionNotOwned) {
+			http.Error(w, fmt.Sprintf("session '%s' not found", sessionID), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set(defaultSessionHeaderKey, sessionID)
+	if len(output) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(output)
+}
+
+// Node returns the cluster node, or nil when clustering is disabled.
+func (h *Handler) Node() *cluster.Node {
+	return h.node
+}
+
 // handleOPTIONS responds to CORS preflight requests when needed.
 func (h *Handler) handleOPTIONS(w http.ResponseWriter, r *http.Request) {
 	h.setCORSHeaders(w, r)
@@ -271,18 +417,7 @@ func (h *Handler) handleOPTIONS(w http.ResponseWriter, r *http.Request) {
 
 // setCORSHeaders sets Access-Control headers depending on options and request origin.
 func (h *Handler) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
-	origin := r.Header.Get("Origin")
-	if h.Options.AllowCredentials {
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		for _, allowed := range h.Options.AllowedOrigins {
-			if allowed == origin {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				return
-			}
-		}
-		return
-	}
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	common.SetCORSHeaders(w, r, h.Options.AllowCredentials, h.Options.AllowedOrigins, h.Options.OriginValidator)
 }
 
 // handleLogoutAll revokes the BFF auth grant and clears the auth cookie.
@@ -309,14 +444,34 @@ func (h *Handler) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// authGrant resolves the BFF auth grant referenced by the auth cookie, if configured.
+func (h *Handler) authGrant(r *http.Request) *authpkg.Grant {
+	return common.AuthGrant(r, h.Options.AuthStore, h.authCookieName())
+}
+
 func (h *Handler) authCookieValue(r *http.Request) string {
+	return common.AuthCookieValue(r, h.authCookieName())
+}
+
+func (h *Handler) authCookieName() string {
 	if h.Options.AuthCookie == nil {
 		return ""
 	}
-	if ck, err := r.Cookie(h.Options.AuthCookie.Name); err == nil {
-		return ck.Value
+	return h.Options.AuthCookie.Name
+}
+
+// sessionIDs returns the signer of session ids bound to the request principal or BFF grant
+func (h *Handler) sessionIDs() *common.SessionIDs {
+	return &common.SessionIDs{Signer: h.Options.SessionSigner, Store: h.Options.AuthStore, AuthCookie: h.authCookieName()}
+}
+
+// csrfCookies returns names of cookies that authenticate requests
+func (h *Handler) csrfCookies() []string {
+	var sessionCookie string
+	if h.Options.CookieSession != nil {
+		sessionCookie = h.Options.CookieSession.Name
 	}
-	return ""
+	return common.CSRFCookies(sessionCookie, h.authCookieName())
 }
 
 func (h *Handler) setAuthCookie(w http.ResponseWriter, r *http.Request, id string) {
@@ -337,7 +492,7 @@ func (h *Handler) setAuthCookie(w http.ResponseWriter, r *http.Request, id strin
 		MaxAge:   h.Options.AuthCookie.MaxAge,
 		Secure:   h.Options.AuthCookie.Secure,
 		HttpOnly: h.Options.AuthCookie.HttpOnly,
-		SameSite: h.Options.AuthCookie.SameSite,
+		SameSite: h.Options.CSRF.SameSite(h.Options.AuthCookie.SameSite),
 	}
 	if ck.Path == "" {
 		ck.Path = "/"
@@ -363,7 +518,7 @@ func (h *Handler) clearAuthCookie(w http.ResponseWriter, r *http.Request) {
 		MaxAge:   -1,
 		Secure:   h.Options.AuthCookie.Secure,
 		HttpOnly: h.Options.AuthCookie.HttpOnly,
-		SameSite: h.Options.AuthCookie.SameSite,
+		SameSite: h.Options.CSRF.SameSite(h.Options.AuthCookie.SameSite),
 	}
 	if ck.Path == "" {
 		ck.Path = "/"
@@ -404,6 +559,14 @@ func hasID(data []byte) bool {
 	return tmp.ID != nil
 }
 
+// applyEventBuffer configures session replay buffer limits.
+func (h *Handler) applyEventBuffer(aSession *base.Session) {
+	base.WithEventOverflowPolicy(h.Options.OverflowPolicy)(aSession)
+	base.WithEventBufferBytes(h.Options.MaxEventBufferBytes)(aSession)
+	base.WithEventBuffer(h.Options.MaxEventBuffer)(aSession)
+	base.WithEventBudget(h.budget)(aSession)
+}
+
 // New constructs Handler with default settings and provided options.
 func New(newHandler transport.NewHandler, opts ...Option) *Handler {
 	h := &Handler{
@@ -420,6 +583,7 @@ func New(newHandler transport.NewHandler, opts ...Option) *Handler {
 			RemovalPolicy:        base.RemovalAfterGrace,
 			KeepAliveInterval:    30 * time.Second,
 			RehydrateOnHandshake: true,
+			DetachedSendTimeout:  30 * time.Second,
 		},
 		base: base.NewHandler(),
 		options: []base.Option{
@@ -429,68 +593,83 @@ func New(newHandler transport.NewHandler, opts ...Option) *Handler {
 	for _, o := range opts {
 		o(&h.Options)
 	}
+	if h.Options.ResourceMetadata != nil {
+		if err := h.Options.ResourceMetadata.Validate(); err != nil {
+			h.base.Logger.Errorf("resource metadata is not served: %v", err)
+		}
+		// the authenticator may be shared across handlers, so metadata is set on a copy
+		if h.Options.BearerAuth != nil && h.Options.BearerAuth.Metadata() == nil {
+			h.Options.BearerAuth = h.Options.BearerAuth.With(authpkg.WithResourceMetadata(h.Options.ResourceMetadata))
+		}
+	}
+	if h.Options.CookieSession != nil {
+		if err := h.Options.CSRF.ValidateSameSite(h.Options.CookieSession.Name, h.Options.CookieSession.SameSite); err != nil {
+			h.base.Logger.Errorf("csrf: %v", err)
+		}
+	}
+	for _, listener := range h.Options.SessionListeners {
+		h.base.Events.Subscribe(listener)
+	}
+	if h.Options.EventMemoryBudget > 0 {
+		h.budget = base.NewEventBudget(h.Options.EventMemoryBudget)
+	}
 	// allow custom session store injection
 	if h.Options.Store != nil {
 		h.base.Sessions = h.Options.Store
 	}
-	// start cleanup sweeper if configured
-	if h.Options.CleanupInterval > 0 {
-		go h.runSweeper()
+	h.lifecycle = base.NewLifecycle(h.base, h.lifecycleOptions())
+	h.broker = base.NewBroker(h.base, h.Options.BrokerOptions...)
+	h.jobs = base.NewJobs(h.base, h.Options.JobOptions...)
+	h.base.Authorizer = h.Options.Authorizer
+	if h.Options.Cluster != nil {
+		h.node = cluster.NewNode(h.Options.Cluster.Node, h.Options.Cluster.Bus, h.Options.Cluster.Registry, h.base, cluster.WithForwardTimeout(h.Options.Cluster.Timeout))
+		if err := h.node.Start(); err != nil {
+			h.base.Logger.Errorf("failed to join cluster: %v", err)
+			h.node = nil
+		}
 	}
+	// start cleanup sweeper if configured
+	h.lifecycle.Start()
 	return h
 }
 
-// runSweeper periodically removes sessions based on lifecycle options.
-func (h *Handler) runSweeper() {
-	ticker := time.NewTicker(h.Options.CleanupInterval)
-	defer ticker.Stop()
-	for range ticker.C {
-		now := time.Now()
-		var toDelete []string
-		h.base.Sessions.Range(func(id string, sess *base.Session) bool {
-			remove := false
-			// Max lifetime
-			if h.Options.MaxLifetime > 0 && now.Sub(sess.CreatedAt) > h.Options.MaxLifetime {
-				remove = true
-			}
-			// Idle TTL
-			if !remove && h.Options.IdleTTL > 0 && now.Sub(sess.LastSeen) > h.Options.IdleTTL {
-				remove = true
-			}
-			// Policy-based detach handling
-			if !remove {
-				switch h.Options.RemovalPolicy {
-				case base.RemovalOnDisconnect:
-					if sess.State == base.SessionStateDetached {
-						remove = true
-					}
-				case base.RemovalAfterGrace:
-					if sess.State == base.SessionStateDetached && h.Options.ReconnectGrace > 0 && sess.DetachedAt != nil {
-						if now.Sub(*sess.DetachedAt) > h.Options.ReconnectGrace {
-							remove = true
-						}
-					}
-				case base.RemovalAfterIdle:
-					// already covered by IdleTTL; nothing extra
-				case base.RemovalManual:
-					// do nothing here
-				}
-			}
-			if remove {
-				toDelete = append(toDelete, id)
-			}
-			return true
-		})
-		for _, id := range toDelete {
-			if h.Options.OnSessionClose != nil {
-				if sess, ok := h.base.Sessions.Get(id); ok {
-					func() {
-						defer func() { _ = recover() }()
-						h.Options.OnSessionClose(sess)
-					}()
-				}
-			}
-			h.base.Sessions.Delete(id)
-		}
+// lifecycleOptions returns session lifecycle settings.
+func (h *Handler) lifecycleOptions() base.LifecycleOptions {
+	ret := base.LifecycleOptions{
+		ReconnectGrace:  h.Options.ReconnectGrace,
+		IdleTTL:         h.Options.IdleTTL,
+		MaxLifetime:     h.Options.MaxLifetime,
+		CleanupInterval: h.Options.CleanupInterval,
+		RemovalPolicy:   h.Options.RemovalPolicy,
+		Policy:          h.Options.ExpiryPolicy,
 	}
+	if h.Options.OnSessionClose != nil {
+		ret.OnClose = append(ret.OnClose, h.Options.OnSessionClose)
+	}
+	return ret
+}
+
+// Sessions returns the session store.
+func (h *Handler) Sessions() base.SessionStore {
+	return h.base.Sessions
+}
+
+// Lifecycle returns the session lifecycle manager.
+func (h *Handler) Lifecycle() *base.Lifecycle {
+	return h.lifecycle
+}
+
+// Broker returns the session publish/subscribe broker.
+func (h *Handler) Broker() *base.Broker {
+	return h.broker
+}
+
+// Jobs returns the asynchronous job runner.
+func (h *Handler) Jobs() *base.Jobs {
+	return h.jobs
+}
+
+// Stop stops the session cleanup sweeper.
+func (h *Handler) Stop() {
+	h.lifecycle.Stop()
 }
@@ -0,0 +1,48 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+)
+
+func TestStreamable_ProtectedResourceMetadata(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		return &auth.Principal{Subject: "alice", Audience: []string{token}}, nil
+	})
+	metadata := &auth.ProtectedResourceMetadata{Resource: "https://api.example.com/mcp", AuthorizationServers: []string{"https://issuer.example"}}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+		WithProtectedResourceMetadata(metadata),
+	)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/oauth-protected-resource/mcp", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "metadata is served without a token")
+	assert.Contains(t, w.Body.String(), `"authorization_servers":["https://issuer.example"]`)
+
+	post := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	w = post("")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="mcp", resource_metadata="https://api.example.com/.well-known/oauth-protected-resource/mcp"`, w.Header().Get("WWW-Authenticate"))
+	w = post("https://other.example.com")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "token issued for another resource")
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.Equal(t, http.StatusOK, post("https://api.example.com/mcp").Code)
+}
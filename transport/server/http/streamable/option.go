@@ -3,6 +3,8 @@ package streamable
 import (
 	"github.com/viant/jsonrpc/transport/server/auth"
 	"github.com/viant/jsonrpc/transport/server/base"
+	"github.com/viant/jsonrpc/transport/server/cluster"
+	"github.com/viant/jsonrpc/transport/server/http/common"
 	"github.com/viant/jsonrpc/transport/server/http/session"
 	"net/http"
 	"time"
@@ -22,9 +24,15 @@ type Options struct {
 	MaxLifetime     time.Duration
 	CleanupInterval time.Duration
 	MaxEventBuffer  int
-	OnSessionClose  func(*base.Session)
-	RemovalPolicy   base.RemovalPolicy
-	OverflowPolicy  base.OverflowPolicy
+	// MaxEventBufferBytes bounds per-session replay buffer by total bytes (zero means unbounded).
+	MaxEventBufferBytes int
+	// EventMemoryBudget bounds replay buffer memory across all sessions (zero means unbounded).
+	EventMemoryBudget int64
+	OnSessionClose    func(*base.Session)
+	RemovalPolicy     base.RemovalPolicy
+	// ExpiryPolicy replaces built-in expiry rules (MaxLifetime, IdleTTL, RemovalPolicy) when set.
+	ExpiryPolicy   base.ExpiryPolicy
+	OverflowPolicy base.OverflowPolicy
 	// Optional custom session store (e.g., Redis-backed). Defaults to in-memory.
 	Store base.SessionStore
 
@@ -33,6 +41,8 @@ type Options struct {
 	// CORS settings for browsers
 	AllowedOrigins   []string
 	AllowCredentials bool
+	// OriginValidator rejects requests with disallowed Origin or Host (DNS rebinding protection) before any session is created.
+	OriginValidator *common.OriginValidator
 
 	// If true and CookieSession.Domain is empty, set cookie Domain to the request's top domain (eTLD+1).
 	CookieUseTopDomain bool
@@ -43,10 +53,49 @@ type Options struct {
 	AuthCookieUseTopDomain bool
 	RehydrateOnHandshake   bool
 	LogoutAllPath          string
+	// DeviceBinding checks grant User-Agent and IP hints on rehydrate (strict, warn or off).
+	DeviceBinding *auth.DeviceBinding
+	// CSRF protects cookie-authenticated unsafe requests against cross-site request forgery.
+	CSRF *common.CSRFPolicy
+	// SessionSigner issues HMAC-signed session ids bound to the request principal or BFF grant (random UUIDs when nil).
+	SessionSigner *session.Signer
+
+	// BearerAuth authenticates "Authorization: Bearer" requests; the principal is bound to the session.
+	BearerAuth *auth.BearerAuthenticator
+	// ResourceMetadata is served at the RFC 9728 well-known path and advertised in bearer challenges.
+	ResourceMetadata *auth.ProtectedResourceMetadata
 
 	// KeepAliveInterval controls emission of SSE keepalive frames on the
 	// long-lived GET stream. Zero or negative disables keepalives.
 	KeepAliveInterval time.Duration
+
+	// ForwardHeaders lists HTTP headers exposed to handlers via jsonrpc.RequestInfo.
+	ForwardHeaders []string
+
+	// OutboundQueue enables asynchronous per-session outbound queue (disabled when nil).
+	OutboundQueue *base.QueueOptions
+
+	// DetachedSendTimeout bounds how long server-initiated messages wait for a client stream
+	// to (re)attach before failing with base.ErrClientNotConnected. Zero fails immediately.
+	DetachedSendTimeout time.Duration
+
+	// TripTimeout bounds how long server-initiated requests wait for the client response (default 5m).
+	TripTimeout time.Duration
+
+	// SessionListeners receive session lifecycle events.
+	SessionListeners []base.SessionListener
+
+	// BrokerOptions configure the publish/subscribe broker (e.g. subscription method names).
+	BrokerOptions []base.BrokerOption
+
+	// JobOptions configure the asynchronous job runner (e.g. job store, retention).
+	JobOptions []base.JobOption
+
+	// Authorizer rejects requests with a JSON-RPC error before they reach the handler (e.g. auth.NewMethodAuthorizer).
+	Authorizer base.Authorizer
+
+	// Cluster enables forwarding of messages for sessions held by other replicas (disabled when nil).
+	Cluster *cluster.Config
 }
 
 // Option mutates Options.
@@ -77,6 +126,13 @@ func WithCleanupInterval(d time.Duration) Option { return func(o *Options) { o.C
 // WithMaxEventBuffer sets the default event buffer size used for resumability.
 func WithMaxEventBuffer(n int) Option { return func(o *Options) { o.MaxEventBuffer = n } }
 
+// WithMaxEventBufferBytes bounds per-session replay buffer by total size in bytes.
+func WithMaxEventBufferBytes(n int) Option { return func(o *Options) { o.MaxEventBufferBytes = n } }
+
+// WithEventMemoryBudget bounds replay buffer memory across all sessions; buffers of the oldest
+// detached sessions are evicted first when the budget is exceeded.
+func WithEventMemoryBudget(n int64) Option { return func(o *Options) { o.EventMemoryBudget = n } }
+
 // WithOnSessionClose registers a hook invoked when a session is finally closed.
 func WithOnSessionClose(fn func(*base.Session)) Option {
 	return func(o *Options) { o.OnSessionClose = fn }
@@ -85,6 +141,11 @@ func WithOnSessionClose(fn func(*base.Session)) Option {
 // WithRemovalPolicy sets the session removal policy.
 func WithRemovalPolicy(p base.RemovalPolicy) Option { return func(o *Options) { o.RemovalPolicy = p } }
 
+// WithExpiryPolicy sets a custom function deciding when sessions expire; it replaces the built-in rules.
+func WithExpiryPolicy(policy base.ExpiryPolicy) Option {
+	return func(o *Options) { o.ExpiryPolicy = policy }
+}
+
 // WithOverflowPolicy sets the event buffer overflow policy.
 func WithOverflowPolicy(p base.OverflowPolicy) Option {
 	return func(o *Options) { o.OverflowPolicy = p }
@@ -112,6 +173,11 @@ func WithCORSAllowedOrigins(origins []string) Option {
 	return func(o *Options) { o.AllowedOrigins = origins }
 }
 
+// WithOriginValidator rejects requests whose Origin or Host is not allowed with 403 (e.g. common.LocalhostOnly()).
+func WithOriginValidator(validator *common.OriginValidator) Option {
+	return func(o *Options) { o.OriginValidator = validator }
+}
+
 // WithCORSAllowCredentials toggles Access-Control-Allow-Credentials and credentialed requests.
 func WithCORSAllowCredentials(v bool) Option { return func(o *Options) { o.AllowCredentials = v } }
 
@@ -132,6 +198,36 @@ type BFFAuthCookie struct {
 // WithAuthStore configures the durable store for BFF auth grants.
 func WithAuthStore(store auth.Store) Option { return func(o *Options) { o.AuthStore = store } }
 
+// WithDeviceBinding enforces grant device hints (User-Agent hash, client IP prefix) when rehydrating from the auth cookie;
+// in strict mode a mismatch revokes the grant family and clears the cookie.
+func WithDeviceBinding(binding *auth.DeviceBinding) Option {
+	return func(o *Options) { o.DeviceBinding = binding }
+}
+
+// WithCSRFPolicy enables CSRF checks for unsafe requests carrying the session or auth cookie;
+// the auth cookie SameSite attribute is raised to policy.MinSameSite.
+func WithCSRFPolicy(policy *common.CSRFPolicy) Option {
+	return func(o *Options) { o.CSRF = policy }
+}
+
+// WithSessionSigner issues signed session ids bound to the bearer principal or BFF grant family; ids that are
+// tampered with, signed with a retired key or presented by a different principal are rejected with 403.
+func WithSessionSigner(signer *session.Signer) Option {
+	return func(o *Options) { o.SessionSigner = signer }
+}
+
+// WithBearerAuth requires a valid bearer token on every request; sessions are bound to the token principal.
+func WithBearerAuth(authenticator *auth.BearerAuthenticator) Option {
+	return func(o *Options) { o.BearerAuth = authenticator }
+}
+
+// WithProtectedResourceMetadata serves RFC 9728 metadata at /.well-known/oauth-protected-resource,
+// advertises it via resource_metadata in bearer challenges and requires tokens issued for metadata.Resource.
+// metadata.Resource must be an absolute https URL (see auth.NewProtectedResourceMetadata); invalid metadata is logged and never served.
+func WithProtectedResourceMetadata(metadata *auth.ProtectedResourceMetadata) Option {
+	return func(o *Options) { o.ResourceMetadata = metadata }
+}
+
 // WithBFFAuthCookie configures the cookie used to carry the BFF auth grant id.
 func WithBFFAuthCookie(c *BFFAuthCookie) Option { return func(o *Options) { o.AuthCookie = c } }
 
@@ -151,3 +247,53 @@ func WithLogoutAllPath(path string) Option { return func(o *Options) { o.LogoutA
 func WithKeepAliveInterval(d time.Duration) Option {
 	return func(o *Options) { o.KeepAliveInterval = d }
 }
+
+// WithForwardedHeaders sets the allow-list of HTTP headers exposed to handlers via jsonrpc.RequestInfo.
+func WithForwardedHeaders(names ...string) Option {
+	return func(o *Options) { o.ForwardHeaders = append(o.ForwardHeaders, names...) }
+}
+
+// WithOutboundQueue enables a bounded asynchronous per-session outbound queue with the given depth, byte limit and full policy.
+func WithOutboundQueue(options base.QueueOptions) Option {
+	return func(o *Options) { o.OutboundQueue = &options }
+}
+
+// WithDetachedSendTimeout sets how long server-initiated requests and notifications are held
+// while the client stream is detached. Set to 0 to fail immediately with base.ErrClientNotConnected.
+func WithDetachedSendTimeout(d time.Duration) Option {
+	return func(o *Options) { o.DetachedSendTimeout = d }
+}
+
+// WithTripTimeout sets how long server-initiated requests (e.g. sampling, elicitation) wait for the client response.
+// Use transport.WithTripTimeout(ctx, d) to override it for a single call.
+func WithTripTimeout(d time.Duration) Option {
+	return func(o *Options) { o.TripTimeout = d }
+}
+
+// WithSessionListener registers a listener receiving session lifecycle events
+// (created, attached, detached, reattached, overflowed, expired, deleted).
+func WithSessionListener(listener base.SessionListener) Option {
+	return func(o *Options) { o.SessionListeners = append(o.SessionListeners, listener) }
+}
+
+// WithBrokerOptions configures the publish/subscribe broker returned by Handler.Broker.
+func WithBrokerOptions(options ...base.BrokerOption) Option {
+	return func(o *Options) { o.BrokerOptions = append(o.BrokerOptions, options...) }
+}
+
+// WithJobOptions configures the asynchronous job runner returned by Handler.Jobs (e.g. job store).
+func WithJobOptions(options ...base.JobOption) Option {
+	return func(o *Options) { o.JobOptions = append(o.JobOptions, options...) }
+}
+
+// WithAuthorizer rejects requests not allowed by authorizer (e.g. auth.NewMethodAuthorizer) with a JSON-RPC error
+// before they reach the handler; the authorizer sees the bearer principal and BFF grant of the request.
+func WithAuthorizer(authorizer base.Authorizer) Option {
+	return func(o *Options) { o.Authorizer = authorizer }
+}
+
+// WithCluster joins the handler to a cluster so that POSTs for sessions owned by another replica
+// are forwarded to the owner instead of failing with 404.
+func WithCluster(config cluster.Config) Option {
+	return func(o *Options) { o.Cluster = &config }
+}
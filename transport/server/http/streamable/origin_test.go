@@ -0,0 +1,47 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
+	"github.com/viant/jsonrpc/transport/server/http/common"
+)
+
+func TestStreamable_OriginValidation(t *testing.T) {
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &serverHandler{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithOriginValidator(common.LocalhostOnly()),
+	)
+	var testCases = []struct {
+		description string
+		host        string
+		origin      string
+		expect      int
+	}{
+		{description: "rebinding host", host: "attacker.example:8080", origin: "http://attacker.example:8080", expect: http.StatusForbidden},
+		{description: "cross-site origin", host: "127.0.0.1:8080", origin: "https://attacker.example", expect: http.StatusForbidden},
+		{description: "local client", host: "127.0.0.1:8080", origin: "http://localhost:3000", expect: http.StatusOK},
+	}
+	for _, testCase := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+		r.Host = testCase.host
+		r.Header.Set("Origin", testCase.origin)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, testCase.expect, w.Code, testCase.description)
+		sessions := 0
+		h.Sessions().Range(func(string, *base.Session) bool { sessions++; return true })
+		if testCase.expect == http.StatusForbidden {
+			assert.Equal(t, 0, sessions, testCase.description)
+		} else {
+			assert.Equal(t, 1, sessions, testCase.description)
+		}
+	}
+}
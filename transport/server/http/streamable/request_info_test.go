@@ -0,0 +1,58 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc"
+	"github.com/viant/jsonrpc/transport"
+)
+
+type infoHandler struct {
+	info *jsonrpc.RequestInfo
+}
+
+func (h *infoHandler) Serve(ctx context.Context, _ *jsonrpc.Request, resp *jsonrpc.Response) {
+	h.info, _ = jsonrpc.RequestInfoFromContext(ctx)
+	resp.Result = []byte(`{}`)
+}
+
+func (h *infoHandler) OnNotification(_ context.Context, _ *jsonrpc.Notification) {}
+
+func TestStreamable_RequestInfo(t *testing.T) {
+	handler := &infoHandler{}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return handler },
+		WithURI("/mcp-info"),
+		WithCleanupInterval(0),
+		WithForwardedHeaders("X-Tenant"),
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/mcp-info", h)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/mcp-info", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Secret", "hidden")
+	req.Header.Set("MCP-Protocol-Version", "2025-06-18")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+
+	info := handler.info
+	if !assert.NotNil(t, info) {
+		return
+	}
+	assert.Equal(t, jsonrpc.TransportStreamable, info.Transport)
+	assert.Equal(t, resp.Header.Get(defaultSessionHeaderKey), info.SessionID)
+	assert.Equal(t, "2025-06-18", info.ProtocolVersion)
+	assert.Equal(t, "acme", info.Header.Get("X-Tenant"))
+	assert.Equal(t, "", info.Header.Get("X-Secret"))
+	assert.NotEmpty(t, info.RemoteAddr)
+}
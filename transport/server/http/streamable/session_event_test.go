@@ -0,0 +1,117 @@
+package streamable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
+)
+
+type eventRecorder struct {
+	mux    sync.Mutex
+	events []*base.SessionEvent
+}
+
+func (r *eventRecorder) record(event *base.SessionEvent) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) types() []base.SessionEventType {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	var result []base.SessionEventType
+	for _, event := range r.events {
+		result = append(result, event.Type)
+	}
+	return result
+}
+
+func (r *eventRecorder) last() *base.SessionEvent {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func TestStreamable_SessionEvents(t *testing.T) {
+	recorder := &eventRecorder{}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &serverHandler{} },
+		WithURI("/mcp-events"),
+		WithCleanupInterval(0),
+		WithSessionListener(recorder.record),
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/mcp-events", h)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/mcp-events", "application/json", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	sid := resp.Header.Get(defaultSessionHeaderKey)
+
+	stream := func(lastEventID string) {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/mcp-events", nil)
+		req.Header.Set("Accept", sseMime)
+		req.Header.Set(defaultSessionHeaderKey, sid)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		getResp, err := http.DefaultClient.Do(req)
+		if !assert.Nil(t, err) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		_ = getResp.Body.Close()
+		assert.Eventually(t, func() bool { return recorder.last().Type == base.SessionDetached }, time.Second, 10*time.Millisecond)
+	}
+	stream("")
+	stream("0")
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/mcp-events", nil)
+	req.Header.Set(defaultSessionHeaderKey, sid)
+	delResp, err := http.DefaultClient.Do(req)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = delResp.Body.Close()
+
+	assert.Equal(t, []base.SessionEventType{
+		base.SessionCreated,
+		base.SessionAttached, base.SessionDetached,
+		base.SessionReattached, base.SessionDetached,
+		base.SessionDeleted,
+	}, recorder.types())
+	assert.Equal(t, sid, recorder.last().SessionID)
+}
+
+func TestStreamable_SessionExpiredEvent(t *testing.T) {
+	recorder := &eventRecorder{}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &serverHandler{} },
+		WithURI("/mcp-expired"),
+		WithCleanupInterval(20*time.Millisecond),
+		WithIdleTTL(50*time.Millisecond),
+		WithSessionListener(recorder.record),
+	)
+	mux := http.NewServeMux()
+	mux.Handle("/mcp-expired", h)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/mcp-expired", "application/json", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = resp.Body.Close()
+	assert.Eventually(t, func() bool { return recorder.last().Type == base.SessionExpired }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, base.ExpireIdle, recorder.last().Reason)
+}
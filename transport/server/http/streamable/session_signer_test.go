@@ -0,0 +1,63 @@
+package streamable
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/auth"
+	"github.com/viant/jsonrpc/transport/server/http/session"
+)
+
+func TestStreamable_SessionSigner(t *testing.T) {
+	verifier := auth.TokenVerifierFunc(func(ctx context.Context, token string) (*auth.Principal, error) {
+		return &auth.Principal{Subject: token, Issuer: "test"}, nil
+	})
+	keyring, err := session.NewKeyring(&session.Key{ID: "k1", Secret: bytes.Repeat([]byte("k"), 32)})
+	if !assert.Nil(t, err) {
+		return
+	}
+	h := New(func(ctx context.Context, tr transport.Transport) transport.Handler { return &whoamiHandler{} },
+		WithURI("/mcp"),
+		WithCleanupInterval(0),
+		WithBearerAuth(auth.NewBearerAuthenticator(verifier)),
+		WithSessionSigner(session.NewSigner(keyring)),
+	)
+	post := func(token, sessionID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"whoami"}`))
+		r.Header.Set("Authorization", "Bearer "+token)
+		if sessionID != "" {
+			r.Header.Set(defaultSessionHeaderKey, sessionID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	sessionID := post("alice", "").Header().Get(defaultSessionHeaderKey)
+	if !assert.True(t, strings.HasPrefix(sessionID, "k1."), sessionID) {
+		return
+	}
+	_, err = h.Options.SessionSigner.Verify(sessionID, (&auth.Principal{Subject: "alice", Issuer: "test"}).Fingerprint())
+	assert.Nil(t, err)
+
+	var testCases = []struct {
+		description string
+		token       string
+		sessionID   string
+		expectCode  int
+	}{
+		{description: "owner", token: "alice", sessionID: sessionID, expectCode: http.StatusOK},
+		{description: "different principal", token: "bob", sessionID: sessionID, expectCode: http.StatusForbidden},
+		{description: "tampered id", token: "alice", sessionID: strings.Replace(sessionID, ".", ".0", 2), expectCode: http.StatusForbidden},
+		{description: "unsigned id", token: "alice", sessionID: "00000000-0000-0000-0000-000000000000", expectCode: http.StatusForbidden},
+	}
+	for _, testCase := range testCases {
+		w := post(testCase.token, testCase.sessionID)
+		assert.Equal(t, testCase.expectCode, w.Code, testCase.description)
+	}
+}
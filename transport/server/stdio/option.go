@@ -1,6 +1,10 @@
 package stdio
 
-import "io"
+import (
+	"github.com/viant/jsonrpc/transport/server/base"
+	"io"
+	"time"
+)
 
 // Option represents a functional option for configuring the stdio transport
 type Option func(*Server)
@@ -29,3 +33,31 @@ func WithLogger(logger *Logger) Option {
 		t.logger = logger
 	}
 }
+
+// WithTripTimeout sets how long server-initiated requests wait for the client response
+func WithTripTimeout(timeout time.Duration) Option {
+	return func(t *Server) {
+		t.options = append(t.options, base.WithTripTimeout(timeout))
+	}
+}
+
+// WithSessionListener registers a listener receiving session lifecycle events
+func WithSessionListener(listener base.SessionListener) Option {
+	return func(t *Server) {
+		t.listeners = append(t.listeners, listener)
+	}
+}
+
+// WithJobOptions configures the asynchronous job runner returned by Server.Jobs (e.g. job store)
+func WithJobOptions(options ...base.JobOption) Option {
+	return func(t *Server) {
+		t.jobOptions = append(t.jobOptions, options...)
+	}
+}
+
+// WithAuthorizer rejects requests not allowed by authorizer before they reach the handler
+func WithAuthorizer(authorizer base.Authorizer) Option {
+	return func(t *Server) {
+		t.base.Authorizer = authorizer
+	}
+}
@@ -16,13 +16,17 @@ const sessionKey = "stdio"
 
 // Server represents a server that handles incoming requests and responses
 type Server struct {
-	base      *base.Handler
-	inout     io.ReadCloser
-	reader    *bufio.Reader
-	ctx       context.Context
-	errWriter io.Writer // Error writer for logging errors, defaults to os.Stderr
-	logger    *Logger   // Custom logger for logging messages
-	options   []base.Option
+	base       *base.Handler
+	inout      io.ReadCloser
+	reader     *bufio.Reader
+	ctx        context.Context
+	errWriter  io.Writer // Error writer for logging errors, defaults to os.Stderr
+	logger     *Logger   // Custom logger for logging messages
+	options    []base.Option
+	broker     *base.Broker
+	jobs       *base.Jobs
+	jobOptions []base.JobOption
+	listeners  []base.SessionListener
 }
 
 func (t *Server) ListenAndServe() error {
@@ -46,6 +50,9 @@ func (t *Server) ListenAndServe() error {
 		}
 		line, err := t.readLine(t.ctx)
 		if err != nil {
+			if session, ok := t.base.Sessions.Get(sessionKey); ok {
+				t.base.Events.Emit(base.SessionDetached, session)
+			}
 			if err == io.EOF {
 				return nil
 			}
@@ -55,10 +62,16 @@ func (t *Server) ListenAndServe() error {
 		if !ok {
 			return fmt.Errorf("session not found")
 		}
-		t.base.HandleMessage(t.ctx, session, []byte(line), nil)
+		t.base.HandleMessage(t.sessionContext(session), session, []byte(line), nil)
 	}
 }
 
+// sessionContext returns server context enriched with session and request info.
+func (t *Server) sessionContext(session *base.Session) context.Context {
+	ctx := context.WithValue(t.ctx, jsonrpc.SessionKey, session)
+	return jsonrpc.WithRequestInfo(ctx, &jsonrpc.RequestInfo{Transport: jsonrpc.TransportStdio, SessionID: session.Id})
+}
+
 func (t *Server) readLine(ctx context.Context) (string, error) {
 	if t.reader == nil {
 		return "", fmt.Errorf("reader is not initialized")
@@ -87,6 +100,21 @@ func (t *Server) readLine(ctx context.Context) (string, error) {
 	}
 }
 
+// Subscribe registers a session lifecycle listener and returns a function removing it
+func (t *Server) Subscribe(listener base.SessionListener) func() {
+	return t.base.Events.Subscribe(listener)
+}
+
+// Broker returns the session publish/subscribe broker
+func (t *Server) Broker() *base.Broker {
+	return t.broker
+}
+
+// Jobs returns the asynchronous job runner
+func (t *Server) Jobs() *base.Jobs {
+	return t.jobs
+}
+
 // New creates a new stdio transport instance with the provided handler and options
 func New(ctx context.Context, newHandler transport.NewHandler, options ...Option) *Server {
 
@@ -105,13 +133,20 @@ func New(ctx context.Context, newHandler transport.NewHandler, options ...Option
 	for _, option := range options {
 		option(ret)
 	}
+	for _, listener := range ret.listeners {
+		ret.base.Events.Subscribe(listener)
+	}
+	ret.broker = base.NewBroker(ret.base)
+	ret.jobs = base.NewJobs(ret.base, ret.jobOptions...)
+	ret.options = append(ret.options, base.WithSessionEvents(ret.base.Events))
 	aSession := base.NewSession(ctx, sessionKey, os.Stdout, newHandler, ret.options...)
-	ctx = context.WithValue(ctx, jsonrpc.SessionKey, aSession)
 	ret.base.Sessions.Put(sessionKey, aSession)
 	// Apply all options
 	for _, opt := range options {
 		opt(ret)
 	}
+	ret.base.Events.Emit(base.SessionCreated, aSession)
+	ret.base.Events.Emit(base.SessionAttached, aSession)
 
 	// Initialize the reader if not already done by options
 	if ret.reader == nil && ret.inout != nil {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"github.com/viant/jsonrpc"
 	"github.com/viant/jsonrpc/transport"
+	"github.com/viant/jsonrpc/transport/server/base"
 	"io"
 	"strings"
 	"testing"
@@ -237,4 +238,16 @@ func TestServer_Options(t *testing.T) {
 			t.Errorf("WithLogger() did not set the logger")
 		}
 	})
+
+	t.Run("WithSessionListener", func(t *testing.T) {
+		created := 0
+		New(context.Background(), mockNewHandler, WithSessionListener(func(event *base.SessionEvent) {
+			if event.Type == base.SessionCreated {
+				created++
+			}
+		}))
+		if created != 1 {
+			t.Errorf("WithSessionListener() listener received %d created events, want 1", created)
+		}
+	})
 }
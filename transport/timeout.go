@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// CancelledNotification is the notification method sent to the peer when a pending request is abandoned
+const CancelledNotification = "notifications/cancelled"
+
+// CancelledParams represents cancelled notification parameters
+type CancelledParams struct {
+	RequestId any    `json:"requestId"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type tripTimeoutKey string
+
+// TripTimeoutKey is the key used to store per-call round trip timeout in the context.
+const TripTimeoutKey = tripTimeoutKey("jsonrpc-trip-timeout")
+
+// WithTripTimeout returns context overriding the round trip timeout for a single call
+func WithTripTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, TripTimeoutKey, timeout)
+}
+
+// TripTimeoutFromContext returns per-call round trip timeout, if any
+func TripTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	timeout, ok := ctx.Value(TripTimeoutKey).(time.Duration)
+	return timeout, ok && timeout > 0
+}
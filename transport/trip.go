@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/viant/jsonrpc"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// ErrTimeout is returned when a round trip does not complete within its timeout
+var ErrTimeout = errors.New("timeout")
+
 // RoundTrip represents a trip
 type RoundTrip struct {
 	Request  *jsonrpc.Request
@@ -31,7 +35,7 @@ func (t *RoundTrip) Wait(ctx context.Context, timeout time.Duration) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(timeout):
-		return errors.New("timeout")
+		return ErrTimeout
 	case <-t.done:
 		if t.err != nil {
 			return t.err
@@ -57,6 +61,7 @@ func (t *RoundTrip) SetResponse(response *jsonrpc.Response) {
 
 // RoundTrips represents a collection of trips
 type RoundTrips struct {
+	mux      sync.Mutex
 	counter  uint64
 	Ring     []*RoundTrip
 	next     uint64
@@ -66,11 +71,15 @@ type RoundTrips struct {
 
 // CloseWithError closes trips with error
 func (r *RoundTrips) CloseWithError(err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	r.error = err
 }
 
 // Match matches a trip by id
 func (r *RoundTrips) Match(id any) (*RoundTrip, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if r.error != nil {
 		return nil, r.error
 	}
@@ -92,6 +101,8 @@ func (r *RoundTrips) Match(id any) (*RoundTrip, error) {
 
 // Add adds a new trip
 func (r *RoundTrips) Add(request *jsonrpc.Request) (*RoundTrip, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if r.error != nil {
 		return nil, r.error
 	}
@@ -111,8 +122,35 @@ func (r *RoundTrips) Add(request *jsonrpc.Request) (*RoundTrip, error) {
 	return nil, fmt.Errorf("failed to add request, ring is full")
 }
 
+// Remove removes pending trip, so that late responses are no longer matched
+func (r *RoundTrips) Remove(trip *RoundTrip) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for i := 0; i < r.capacity; i++ {
+		if r.Ring[i] == trip {
+			r.Ring[i] = nil
+			return
+		}
+	}
+}
+
+// Pending returns number of in-flight trips
+func (r *RoundTrips) Pending() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	count := 0
+	for i := 0; i < r.capacity; i++ {
+		if r.Ring[i] != nil {
+			count++
+		}
+	}
+	return count
+}
+
 // Get returns the trip at the given index
 func (r *RoundTrips) Get(index int) *RoundTrip {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if index < 0 || index >= r.capacity {
 		return nil
 	}
@@ -121,6 +159,8 @@ func (r *RoundTrips) Get(index int) *RoundTrip {
 
 // Size returns the size of the trips
 func (r *RoundTrips) Size() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	if int(r.counter) < r.capacity {
 		return int(r.counter)
 	}